@@ -1,18 +1,30 @@
 package main
 
 import (
+	"fmt"
 	"main/core/config"
-	"main/core/database/sqlite"
+	"main/core/database"
+	"main/core/jobs"
 	"main/core/logger"
 	"main/core/server"
+	"os"
 )
 
 func init() {
 	config.Init()
 	logger.Init()
-	sqlite.Init()
 }
 
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		if err := runMigrate(os.Args[2:]); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	database.Init()
+	jobs.StartTrashPurge()
 	server.Run()
 }
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"main/core/database"
+	"strconv"
+)
+
+const migrateUsage = `usage: api migrate [up | down [N] | status]`
+
+// runMigrate implements the migrate subcommand:
+//
+//	api migrate [up]      apply pending migrations
+//	api migrate down [N]  revert the last N migrations, 1 by default
+//	api migrate status    list migrations and when they were applied
+func runMigrate(args []string) error {
+	command := "up"
+	if len(args) > 0 {
+		command, args = args[0], args[1:]
+	}
+
+	migrator, err := database.Migrator()
+	if err != nil {
+		return err
+	}
+	defer migrator.DB.Close()
+
+	switch {
+	case command == "up" && len(args) == 0:
+		n, err := migrator.Up()
+		fmt.Printf("applied %d migrations\n", n)
+		return err
+	case command == "down" && len(args) <= 1:
+		steps := 1
+		if len(args) == 1 {
+			steps, err = strconv.Atoi(args[0])
+			if err != nil || steps < 1 {
+				return errors.New(migrateUsage)
+			}
+		}
+		n, err := migrator.Down(steps)
+		fmt.Printf("reverted %d migrations\n", n)
+		return err
+	case command == "status" && len(args) == 0:
+		states, err := migrator.Status()
+		if err != nil {
+			return err
+		}
+		for _, state := range states {
+			appliedAt := "pending"
+			if state.AppliedAt != "" {
+				appliedAt = state.AppliedAt
+			}
+			fmt.Printf("%04d %-32s %s\n", state.Version, state.Name, appliedAt)
+		}
+		return nil
+	}
+	return errors.New(migrateUsage)
+}
@@ -3,7 +3,9 @@ package config
 import (
 	"fmt"
 	"github.com/caarlos0/env/v7"
+	"os"
 	"sync"
+	"time"
 )
 
 type Config struct {
@@ -23,7 +25,24 @@ type Config struct {
 		Output string `env:"LOGGER_OUTPUT" envDefault:"json"`
 	}
 	DB struct {
-		Path string `env:"TODO_DBFILE" envDefault:"./scheduler.db"`
+		Driver string `env:"TODO_DB_DRIVER" envDefault:"sqlite"`
+		Path   string `env:"TODO_DBFILE" envDefault:"./scheduler.db"`
+		DSN    string `env:"TODO_DB_DSN" envDefault:""`
+	}
+	Time struct {
+		Zone string `env:"TODO_TZ" envDefault:"Local"`
+	}
+	Holidays struct {
+		File string `env:"TODO_HOLIDAYS_FILE" envDefault:""`
+	}
+	Trash struct {
+		RetentionDays int `env:"TODO_TRASH_RETENTION_DAYS" envDefault:"30"`
+	}
+	Tasks struct {
+		MaxPageSize int `env:"TODO_MAX_PAGE_SIZE" envDefault:"100"`
+	}
+	Attachments struct {
+		Dir string `env:"TODO_ATTACHMENTS_DIR" envDefault:"./attachments"`
 	}
 	Auth struct {
 		Password string `env:"TODO_PASSWORD" envDefault:"1"`
@@ -32,6 +51,7 @@ type Config struct {
 }
 
 var s *Config
+var location *time.Location
 var once sync.Once
 
 func Init() {
@@ -41,6 +61,15 @@ func Init() {
 		if err != nil {
 			panic(fmt.Errorf("failed to parse config: %v", err))
 		}
+		// env replaces empty values with defaults, but an empty password
+		// set on purpose turns authentication off.
+		if password, ok := os.LookupEnv("TODO_PASSWORD"); ok && password == "" {
+			s.Auth.Password = ""
+		}
+		location, err = time.LoadLocation(s.Time.Zone)
+		if err != nil {
+			panic(fmt.Errorf("failed to load time zone %q: %v", s.Time.Zone, err))
+		}
 	})
 }
 
@@ -50,3 +79,11 @@ func Get() *Config {
 	}
 	return s
 }
+
+// Location returns the time zone task dates and times are interpreted in.
+func Location() *time.Location {
+	if location == nil {
+		Init()
+	}
+	return location
+}
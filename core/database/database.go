@@ -0,0 +1,97 @@
+// Package database opens the storage backend selected by configuration.
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"go.uber.org/zap"
+	"main/core/config"
+	"main/core/database/memory"
+	"main/core/database/migrate"
+	"main/core/database/postgres"
+	"main/core/database/sqlite"
+	"main/core/database/store"
+	"main/core/logger"
+)
+
+const (
+	DriverSQLite   = "sqlite"
+	DriverPostgres = "postgres"
+	DriverMemory   = "memory"
+)
+
+var db store.Store
+
+func Init() {
+	s, err := Open()
+	if err != nil {
+		logger.Get().Fatal("failed to init database", zap.Error(err))
+	}
+	db = s
+}
+
+func Get() store.Store {
+	if db == nil {
+		Init()
+	}
+	return db
+}
+
+// Open creates the configured backend and loads the holidays file into it.
+func Open() (store.Store, error) {
+	var s store.Store
+	switch driver := config.Get().DB.Driver; driver {
+	case DriverSQLite:
+		storage, err := sqlite.New(config.Get().DB.Path)
+		if err != nil {
+			return nil, err
+		}
+		s = storage
+	case DriverPostgres:
+		storage, err := postgres.New(config.Get().DB.DSN)
+		if err != nil {
+			return nil, err
+		}
+		s = storage
+	case DriverMemory:
+		s = memory.New()
+	default:
+		return nil, fmt.Errorf("unknown database driver %q", driver)
+	}
+
+	if path := config.Get().Holidays.File; path != "" {
+		if err := store.LoadHolidays(s, path); err != nil {
+			s.Close()
+			return nil, err
+		}
+	}
+	return s, nil
+}
+
+// Migrator connects to the configured database for managing its migrations
+// by hand. The caller closes the returned migrator's DB.
+func Migrator() (migrate.Migrator, error) {
+	var db *sql.DB
+	var err error
+	var migrator func(db *sql.DB) (migrate.Migrator, error)
+	switch driver := config.Get().DB.Driver; driver {
+	case DriverSQLite:
+		db, err = sqlite.Open(config.Get().DB.Path)
+		migrator = sqlite.Migrator
+	case DriverPostgres:
+		db, err = postgres.Open(config.Get().DB.DSN)
+		migrator = postgres.Migrator
+	default:
+		return migrate.Migrator{}, fmt.Errorf("database driver %q has no migrations", driver)
+	}
+	if err != nil {
+		return migrate.Migrator{}, err
+	}
+
+	m, err := migrator(db)
+	if err != nil {
+		db.Close()
+		return migrate.Migrator{}, err
+	}
+	return m, nil
+}
@@ -0,0 +1,102 @@
+package memory
+
+import (
+	"cmp"
+	"main/core/database/store"
+	"main/internal/models/attachments"
+	"slices"
+	"strconv"
+	"time"
+)
+
+func (s *Storage) Attachments(task string) ([]attachments.Attachment, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key, ok := s.findLive(task)
+	if !ok {
+		return nil, store.ErrNoSuchTask
+	}
+	var result []attachments.Attachment
+	for _, attachment := range s.attachments {
+		if attachment.TaskID == key {
+			result = append(result, attachment)
+		}
+	}
+	slices.SortFunc(result, byAttachmentID)
+	return result, nil
+}
+
+func (s *Storage) AddAttachment(attachment attachments.Attachment) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.findLive(strconv.FormatInt(attachment.TaskID, 10)); !ok {
+		return 0, store.ErrNoSuchTask
+	}
+	s.lastAttachment++
+	attachment.ID = strconv.FormatInt(s.lastAttachment, 10)
+	attachment.CreatedAt = time.Now().UTC().Format(time.RFC3339)
+	s.attachments[s.lastAttachment] = attachment
+	return s.lastAttachment, nil
+}
+
+func (s *Storage) FindAttachment(id string) (*attachments.Attachment, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	attachment, _, ok := s.findAttachment(id)
+	if !ok {
+		return nil, store.ErrNoSuchAttachment
+	}
+	return &attachment, nil
+}
+
+func (s *Storage) DeleteAttachment(id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	_, key, ok := s.findAttachment(id)
+	if !ok {
+		return store.ErrNoSuchAttachment
+	}
+	delete(s.attachments, key)
+	return nil
+}
+
+func (s *Storage) PurgeAttachments() ([]attachments.Attachment, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var result []attachments.Attachment
+	for key, attachment := range s.attachments {
+		if _, ok := s.tasks[attachment.TaskID]; !ok {
+			result = append(result, attachment)
+			delete(s.attachments, key)
+		}
+	}
+	slices.SortFunc(result, byAttachmentID)
+	return result, nil
+}
+
+// findAttachment returns an attachment of a live task of the user by its id.
+func (s *Storage) findAttachment(id string) (attachments.Attachment, int64, bool) {
+	key, err := strconv.ParseInt(id, 10, 64)
+	if err != nil {
+		return attachments.Attachment{}, 0, false
+	}
+	attachment, ok := s.attachments[key]
+	if !ok {
+		return attachments.Attachment{}, 0, false
+	}
+	if _, ok = s.findLive(strconv.FormatInt(attachment.TaskID, 10)); !ok {
+		return attachments.Attachment{}, 0, false
+	}
+	return attachment, key, true
+}
+
+func byAttachmentID(a, b attachments.Attachment) int {
+	x, _ := strconv.ParseInt(a.ID, 10, 64)
+	y, _ := strconv.ParseInt(b.ID, 10, 64)
+	return cmp.Compare(x, y)
+}
@@ -0,0 +1,124 @@
+package memory
+
+import (
+	"main/core/database/store"
+	"main/internal/models/checklists"
+	"slices"
+	"strconv"
+)
+
+func (s *Storage) Checklist(task string) ([]checklists.Item, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key, ok := s.findLive(task)
+	if !ok {
+		return nil, store.ErrNoSuchTask
+	}
+	return s.checklist(key), nil
+}
+
+// findLive returns the key of a task of the user that is not in the trash.
+func (s *Storage) findLive(id string) (int64, bool) {
+	task, key, ok := s.find(id)
+	return key, ok && live(task)
+}
+
+// checklist returns the items of a task in order.
+func (s *Storage) checklist(task int64) []checklists.Item {
+	var result []checklists.Item
+	for _, item := range s.items {
+		if item.TaskID == task {
+			result = append(result, item)
+		}
+	}
+	// Positions of a task's items are unique, as AddItem appends after the
+	// last one and ReorderChecklist numbers them all.
+	slices.SortFunc(result, func(a, b checklists.Item) int {
+		return a.Position - b.Position
+	})
+	return result
+}
+
+func (s *Storage) AddItem(task string, text string) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key, ok := s.findLive(task)
+	if !ok {
+		return 0, store.ErrNoSuchTask
+	}
+	position := 1
+	if items := s.checklist(key); len(items) > 0 {
+		position = items[len(items)-1].Position + 1
+	}
+	s.lastItem++
+	s.items[s.lastItem] = checklists.Item{
+		ID:       strconv.FormatInt(s.lastItem, 10),
+		TaskID:   key,
+		Position: position,
+		Text:     text,
+	}
+	return s.lastItem, nil
+}
+
+func (s *Storage) ReorderChecklist(task string, ids []string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key, ok := s.findLive(task)
+	if !ok {
+		return store.ErrNoSuchTask
+	}
+	if err := store.CheckOrder(s.checklist(key), ids); err != nil {
+		return err
+	}
+	for i, id := range ids {
+		itemKey, _ := strconv.ParseInt(id, 10, 64)
+		item := s.items[itemKey]
+		item.Position = i + 1
+		s.items[itemKey] = item
+	}
+	return nil
+}
+
+func (s *Storage) ToggleItem(id string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	item, key, ok := s.findItem(id)
+	if !ok {
+		return false, store.ErrNoSuchItem
+	}
+	item.Done = !item.Done
+	s.items[key] = item
+	return item.Done, nil
+}
+
+func (s *Storage) DeleteItem(id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	_, key, ok := s.findItem(id)
+	if !ok {
+		return store.ErrNoSuchItem
+	}
+	delete(s.items, key)
+	return nil
+}
+
+// findItem returns an item of a live task of the user by its id.
+func (s *Storage) findItem(id string) (checklists.Item, int64, bool) {
+	key, err := strconv.ParseInt(id, 10, 64)
+	if err != nil {
+		return checklists.Item{}, 0, false
+	}
+	item, ok := s.items[key]
+	if !ok {
+		return checklists.Item{}, 0, false
+	}
+	if _, ok = s.findLive(strconv.FormatInt(item.TaskID, 10)); !ok {
+		return checklists.Item{}, 0, false
+	}
+	return item, key, true
+}
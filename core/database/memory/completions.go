@@ -0,0 +1,35 @@
+package memory
+
+import (
+	"main/internal/models/completions"
+	"time"
+)
+
+func (s *Storage) TaskHistory(id string) ([]completions.Completion, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var result []completions.Completion
+	for _, completion := range s.completions {
+		if completion.UserID == s.user && completion.TaskID == id {
+			result = append(result, completion)
+		}
+	}
+	return result, nil
+}
+
+func (s *Storage) Completions(from time.Time, to time.Time) ([]completions.Completion, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	start := from.UTC().Format(time.RFC3339)
+	end := to.UTC().Format(time.RFC3339)
+	var result []completions.Completion
+	for _, completion := range s.completions {
+		if completion.UserID != s.user || completion.CompletedAt < start || (!to.IsZero() && completion.CompletedAt >= end) {
+			continue
+		}
+		result = append(result, completion)
+	}
+	return result, nil
+}
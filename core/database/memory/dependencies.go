@@ -0,0 +1,97 @@
+package memory
+
+import (
+	"main/core/database/store"
+	"main/internal/models/tasks"
+	"slices"
+	"strconv"
+)
+
+// blocked reports whether a task has open blockers: one-off blockers that are
+// not in the trash, and repeating ones that have not moved past the date of
+// the task.
+func (s *Storage) blocked(key int64) bool {
+	task := s.tasks[key]
+	for _, blocker := range s.blockers[key] {
+		by := s.tasks[blocker]
+		if live(by) && (by.Repeat == "" || by.Date <= task.Date) {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *Storage) Blockers(id string) ([]tasks.Task, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key, ok := s.findLive(id)
+	if !ok {
+		return nil, store.ErrNoSuchTask
+	}
+	blockers := s.blockers[key]
+	return s.list(func(task tasks.Task) bool {
+		blocker, _ := strconv.ParseInt(task.ID, 10, 64)
+		return live(task) && slices.Contains(blockers, blocker)
+	}, byDate), nil
+}
+
+func (s *Storage) AddDependency(id string, blocker string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key, ok := s.findLive(id)
+	if !ok {
+		return store.ErrNoSuchTask
+	}
+	by, ok := s.findLive(blocker)
+	if !ok {
+		return store.ErrNoSuchTask
+	}
+	if key == by || s.dependsOn(by, key) {
+		return store.ErrDependencyCycle
+	}
+	if !slices.Contains(s.blockers[key], by) {
+		s.blockers[key] = append(s.blockers[key], by)
+	}
+	return nil
+}
+
+// dependsOn reports whether a task is blocked by another through a chain of
+// dependencies.
+func (s *Storage) dependsOn(task int64, blocker int64) bool {
+	seen := map[int64]bool{task: true}
+	queue := []int64{task}
+	for len(queue) > 0 {
+		next := queue[0]
+		queue = queue[1:]
+		for _, by := range s.blockers[next] {
+			if by == blocker {
+				return true
+			}
+			if !seen[by] {
+				seen[by] = true
+				queue = append(queue, by)
+			}
+		}
+	}
+	return false
+}
+
+func (s *Storage) DeleteDependency(id string, blocker string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	_, key, ok := s.find(id)
+	if !ok {
+		return store.ErrNoSuchDependency
+	}
+	by, err := strconv.ParseInt(blocker, 10, 64)
+	if err != nil || !slices.Contains(s.blockers[key], by) {
+		return store.ErrNoSuchDependency
+	}
+	s.blockers[key] = slices.DeleteFunc(s.blockers[key], func(b int64) bool {
+		return b == by
+	})
+	return nil
+}
@@ -0,0 +1,70 @@
+package memory
+
+import (
+	"main/core/database/store"
+	"main/internal/models/holidays"
+	"main/internal/models/tasks"
+	"main/pkg"
+	"sort"
+)
+
+func (s *Storage) Holidays() ([]holidays.Holiday, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.holidayList(), nil
+}
+
+func (s *Storage) holidayList() []holidays.Holiday {
+	var result []holidays.Holiday
+	for date, name := range s.holidays {
+		result = append(result, holidays.Holiday{Date: date, Name: name})
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Date < result[j].Date
+	})
+	return result
+}
+
+func (s *Storage) AddHoliday(holiday holidays.Holiday) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.holidays[holiday.Date] = holiday.Name
+	return nil
+}
+
+func (s *Storage) DeleteHoliday(date string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.holidays[date]; !ok {
+		return store.ErrNoSuchHoliday
+	}
+	delete(s.holidays, date)
+	return nil
+}
+
+func (s *Storage) Calendar() (*pkg.Calendar, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.calendar()
+}
+
+// calendar is Calendar for callers that hold the lock.
+func (s *Storage) calendar() (*pkg.Calendar, error) {
+	return store.CalendarOf(s.holidayList())
+}
+
+// shiftTask is store.ShiftTask with the stored holidays.
+func (s *Storage) shiftTask(task *tasks.Task, base string) error {
+	if task.Shift == "" {
+		return store.ShiftTask(task, base, nil)
+	}
+	cal, err := s.calendar()
+	if err != nil {
+		return err
+	}
+	return store.ShiftTask(task, base, cal)
+}
@@ -0,0 +1,132 @@
+package memory
+
+import (
+	"main/core/database/store"
+	"main/internal/models/lists"
+	"main/internal/models/tasks"
+	"sort"
+	"strconv"
+)
+
+func (s *Storage) Lists() ([]lists.List, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var result []lists.List
+	for _, list := range s.lists {
+		if list.UserID != s.user {
+			continue
+		}
+		key, _ := strconv.ParseInt(list.ID, 10, 64)
+		list.Tasks = len(s.list(func(task tasks.Task) bool {
+			return live(task) && task.ListID == key
+		}, nil))
+		result = append(result, list)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Name != result[j].Name {
+			return result[i].Name < result[j].Name
+		}
+		a, _ := strconv.ParseInt(result[i].ID, 10, 64)
+		b, _ := strconv.ParseInt(result[j].ID, 10, 64)
+		return a < b
+	})
+	return result, nil
+}
+
+func (s *Storage) AddList(list lists.List) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.named(list.Name, 0) {
+		return 0, store.ErrListExists
+	}
+	s.lastList++
+	list.ID = strconv.FormatInt(s.lastList, 10)
+	list.Tasks, list.UserID = 0, s.user
+	s.lists[s.lastList] = list
+	return s.lastList, nil
+}
+
+func (s *Storage) UpdateList(list lists.List) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	current, key, ok := s.findList(list.ID)
+	if !ok {
+		return store.ErrNoSuchList
+	}
+	if s.named(list.Name, key) {
+		return store.ErrListExists
+	}
+	current.Name = list.Name
+	s.lists[key] = current
+	return nil
+}
+
+func (s *Storage) DeleteList(id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	_, key, ok := s.findList(id)
+	if !ok {
+		return store.ErrNoSuchList
+	}
+	delete(s.lists, key)
+	for id, task := range s.tasks {
+		if task.UserID == s.user && task.ListID == key {
+			task.ListID = 0
+			s.tasks[id] = task
+		}
+	}
+	return nil
+}
+
+func (s *Storage) MoveTask(id string, list int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !s.hasList(list) {
+		return store.ErrNoSuchList
+	}
+	task, key, ok := s.find(id)
+	if !ok || !live(task) {
+		return store.ErrNoSuchTask
+	}
+	task.ListID = list
+	s.tasks[key] = task
+	return nil
+}
+
+// findList returns a list of the user by its id.
+func (s *Storage) findList(id string) (lists.List, int64, bool) {
+	key, err := strconv.ParseInt(id, 10, 64)
+	if err != nil {
+		return lists.List{}, 0, false
+	}
+	list, ok := s.lists[key]
+	if !ok || list.UserID != s.user {
+		return lists.List{}, 0, false
+	}
+	return list, key, true
+}
+
+// hasList reports whether the user has the list, or list is zero.
+func (s *Storage) hasList(list int64) bool {
+	if list == 0 {
+		return true
+	}
+	_, _, ok := s.findList(strconv.FormatInt(list, 10))
+	return ok
+}
+
+// named reports whether the user has a list called name other than the list
+// with id except.
+func (s *Storage) named(name string, except int64) bool {
+	for key, list := range s.lists {
+		if key != except && list.UserID == s.user && list.Name == name {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,406 @@
+// Package memory is a storage backend that keeps everything in process
+// memory. It is meant for tests and demos; nothing survives a restart.
+package memory
+
+import (
+	"errors"
+	"fmt"
+	"main/core/database/store"
+	"main/internal/models/attachments"
+	"main/internal/models/checklists"
+	"main/internal/models/completions"
+	"main/internal/models/lists"
+	"main/internal/models/tags"
+	"main/internal/models/tasks"
+	"main/internal/models/users"
+	"main/pkg"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Storage is a user's view of the state shared by all users.
+type Storage struct {
+	*state
+	user int64
+}
+
+type state struct {
+	mu             sync.Mutex
+	tasks          map[int64]tasks.Task
+	lastTask       int64
+	holidays       map[string]string
+	completions    []completions.Completion
+	lastCompletion int64
+	users          map[int64]users.User
+	lastUser       int64
+	lists          map[int64]lists.List
+	lastList       int64
+	tags           map[int64]tags.Tag
+	lastTag        int64
+	items          map[int64]checklists.Item
+	lastItem       int64
+	// blockers are the keys of the tasks each task is blocked by.
+	blockers       map[int64][]int64
+	attachments    map[int64]attachments.Attachment
+	lastAttachment int64
+}
+
+var _ store.Store = (*Storage)(nil)
+
+func New() *Storage {
+	return &Storage{state: &state{
+		tasks:       make(map[int64]tasks.Task),
+		holidays:    make(map[string]string),
+		users:       make(map[int64]users.User),
+		lists:       make(map[int64]lists.List),
+		tags:        make(map[int64]tags.Tag),
+		items:       make(map[int64]checklists.Item),
+		blockers:    make(map[int64][]int64),
+		attachments: make(map[int64]attachments.Attachment),
+	}}
+}
+
+func (s *Storage) Close() error {
+	return nil
+}
+
+func (s *Storage) ForUser(id int64) store.Store {
+	return &Storage{state: s.state, user: id}
+}
+
+// find returns a task of the user by its id, including tasks in the trash.
+func (s *Storage) find(id string) (tasks.Task, int64, bool) {
+	key, err := strconv.ParseInt(id, 10, 64)
+	if err != nil {
+		return tasks.Task{}, 0, false
+	}
+	task, ok := s.tasks[key]
+	if task.UserID != s.user {
+		return tasks.Task{}, 0, false
+	}
+	return task, key, ok
+}
+
+// list returns the tasks of the user that match, ordered by less and then by
+// id, with Blocked set for live ones.
+func (s *Storage) list(match func(task tasks.Task) bool, less func(a, b tasks.Task) bool) []tasks.Task {
+	var result []tasks.Task
+	for key, task := range s.tasks {
+		if task.UserID == s.user && match(task) {
+			task.Blocked = live(task) && s.blocked(key)
+			result = append(result, task)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if less != nil && less(result[i], result[j]) != less(result[j], result[i]) {
+			return less(result[i], result[j])
+		}
+		a, _ := strconv.ParseInt(result[i].ID, 10, 64)
+		b, _ := strconv.ParseInt(result[j].ID, 10, 64)
+		return a < b
+	})
+	return result
+}
+
+// page returns a page of a list sorted by sort.
+func page(list []tasks.Task, p store.Page, sort string, desc bool) (store.TaskPage, error) {
+	after, err := p.Cursor(sort, desc)
+	if err != nil {
+		return store.TaskPage{}, err
+	}
+	total := len(list)
+	if after != nil {
+		i := 0
+		for i < len(list) && !follows(store.CursorAt(sort, desc, list[i]), after) {
+			i++
+		}
+		list = list[i:]
+	}
+	if p.Offset >= len(list) {
+		return store.TaskPage{Total: total}, nil
+	}
+	list = list[p.Offset:]
+	if len(list) > p.Size()+1 {
+		list = list[:p.Size()+1]
+	}
+	return store.NewTaskPage(list, p.Size(), total, sort, desc), nil
+}
+
+// follows reports whether the task at cursor a comes after cursor b.
+func follows(a, b *store.Cursor) bool {
+	if c := slices.Compare(a.Key, b.Key); c != 0 {
+		return (c > 0) != a.Desc
+	}
+	return a.ID != b.ID && (a.ID > b.ID) != a.Desc
+}
+
+func live(task tasks.Task) bool {
+	return task.DeletedAt == ""
+}
+
+// byDate orders tasks by date, then by priority, most urgent first, and then
+// by time.
+func byDate(a, b tasks.Task) bool {
+	if a.Date != b.Date {
+		return a.Date < b.Date
+	}
+	if a.Priority != b.Priority {
+		return a.Priority < b.Priority
+	}
+	return a.Time < b.Time
+}
+
+func byTitle(a, b tasks.Task) bool {
+	return a.Title < b.Title
+}
+
+// sortOrders are the orders of each sort field, before ordering by id.
+var sortOrders = map[string]func(a, b tasks.Task) bool{
+	store.SortDate:  byDate,
+	store.SortTitle: byTitle,
+	store.SortID:    nil,
+}
+
+func (s *Storage) AddTaskDB(task tasks.Task) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.shiftTask(&task, task.Date); err != nil {
+		return 0, err
+	}
+	if !s.hasList(task.ListID) {
+		return 0, store.ErrNoSuchList
+	}
+	s.lastTask++
+	task.ID = strconv.FormatInt(s.lastTask, 10)
+	if task.Occurrence == 0 {
+		task.Occurrence = 1
+	}
+	task.DeletedAt = ""
+	task.Blocked = false
+	if task.Priority == 0 {
+		task.Priority = tasks.PriorityLowest
+	}
+	task.UserID = s.user
+	task.Tags = s.useTags(task.Tags)
+	s.tasks[s.lastTask] = task
+	return s.lastTask, nil
+}
+
+func (s *Storage) FindTask(id string) (*tasks.Task, error) {
+	if id == "" {
+		return nil, errors.New("empty task id")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	task, key, ok := s.find(id)
+	if !ok || !live(task) {
+		return nil, store.ErrNoSuchTask
+	}
+	task.Blocked = s.blocked(key)
+	return &task, nil
+}
+
+func (s *Storage) UpdateTask(task tasks.Task) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	current, key, ok := s.find(task.ID)
+	if !ok || !live(current) {
+		return store.ErrNoSuchTask
+	}
+
+	// A shifted task keeps its original date unless the date itself is changed.
+	base := task.Date
+	if current.Date == task.Date && current.Anchor != "" {
+		base = current.Anchor
+	}
+	if err := s.shiftTask(&task, base); err != nil {
+		return err
+	}
+
+	// A changed repeat rule starts a new series, so its occurrence count is reset.
+	task.Occurrence = current.Occurrence
+	if task.Repeat != current.Repeat {
+		task.Occurrence = 1
+	}
+	task.ID, task.DeletedAt, task.UserID, task.ListID = current.ID, "", s.user, current.ListID
+	task.Blocked = false
+	// The priority and tags are kept unless given.
+	if task.Priority == 0 {
+		task.Priority = current.Priority
+	}
+	if task.Tags == nil {
+		task.Tags = current.Tags
+	} else {
+		task.Tags = s.useTags(task.Tags)
+	}
+	s.tasks[key] = task
+	return nil
+}
+
+func (s *Storage) Tasks(query store.TaskQuery) (store.TaskPage, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	field := query.SortField()
+	less, ok := sortOrders[field]
+	if !ok {
+		return store.TaskPage{}, fmt.Errorf("unknown sort field %q", field)
+	}
+
+	list := s.list(func(task tasks.Task) bool {
+		return live(task) &&
+			(query.From == "" || task.Date >= query.From) &&
+			(query.To == "" || task.Date <= query.To) &&
+			(query.List == 0 || task.ListID == query.List) &&
+			(query.Tag == "" || slices.Contains(task.Tags, query.Tag)) &&
+			(query.Priority == 0 || task.Priority == query.Priority) &&
+			(query.Recurring == nil || *query.Recurring == (task.Repeat != ""))
+	}, less)
+	if query.Desc {
+		slices.Reverse(list)
+	}
+	return page(list, query.Page, field, query.Desc)
+}
+
+// SearchTasks returns tasks matching the filter, looking up each term as a
+// substring of the title or comment.
+func (s *Storage) SearchTasks(filter store.Filter, p store.Page) (store.TaskPage, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if filter.Empty() {
+		return store.TaskPage{}, nil
+	}
+	return page(s.list(func(task tasks.Task) bool {
+		return live(task) && matches(task, filter)
+	}, byDate), p, store.SortDate, false)
+}
+
+func matches(task tasks.Task, filter store.Filter) bool {
+	if filter.After != "" && task.Date < filter.After {
+		return false
+	}
+	if filter.Before != "" && task.Date >= filter.Before {
+		return false
+	}
+	if filter.Repeat != nil && *filter.Repeat != (task.Repeat != "") {
+		return false
+	}
+	if filter.Priority != 0 && task.Priority != filter.Priority {
+		return false
+	}
+	for _, tag := range filter.Tags {
+		if !slices.Contains(task.Tags, tag) {
+			return false
+		}
+	}
+	for _, term := range filter.Terms {
+		text := asciiLower(term.Text)
+		found := strings.Contains(asciiLower(task.Title), text) || strings.Contains(asciiLower(task.Comment), text)
+		if found == term.Exclude {
+			return false
+		}
+	}
+	return true
+}
+
+// asciiLower folds case the way SQLite's LIKE does, which ignores case of
+// ASCII letters only.
+func asciiLower(s string) string {
+	return strings.Map(func(r rune) rune {
+		if r >= 'A' && r <= 'Z' {
+			return r + 'a' - 'A'
+		}
+		return r
+	}, s)
+}
+
+func (s *Storage) TasksByDate(date string) ([]tasks.Task, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.list(func(task tasks.Task) bool {
+		return live(task) && task.Date == date
+	}, byDate), nil
+}
+
+func (s *Storage) TasksUntil(date string) ([]tasks.Task, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.list(func(task tasks.Task) bool {
+		return live(task) && task.Date <= date
+	}, byDate), nil
+}
+
+func (s *Storage) DoneTask(id string, note string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	task, key, ok := s.find(id)
+	if !ok || !live(task) {
+		return store.ErrNoSuchTask
+	}
+	if s.blocked(key) {
+		return store.ErrTaskBlocked
+	}
+
+	next, anchor, occurrence := "", "", 0
+	if task.Repeat != "" {
+		cal, err := s.calendar()
+		if err != nil {
+			return err
+		}
+		next, anchor, occurrence, err = store.NextOccurrence(task, cal)
+		if err != nil && !errors.Is(err, pkg.ErrRepeatFinished) {
+			return err
+		}
+	}
+
+	s.lastCompletion++
+	s.completions = append(s.completions, completions.Completion{
+		ID:          strconv.FormatInt(s.lastCompletion, 10),
+		TaskID:      task.ID,
+		Title:       task.Title,
+		Date:        task.Date,
+		CompletedAt: time.Now().UTC().Format(time.RFC3339),
+		Note:        note,
+		UserID:      s.user,
+	})
+
+	if next != "" {
+		task.Date, task.Anchor, task.Occurrence = next, anchor, occurrence
+		// The next occurrence starts with its checklist unchecked.
+		for itemKey, item := range s.items {
+			if item.TaskID == key {
+				item.Done = false
+				s.items[itemKey] = item
+			}
+		}
+	} else {
+		task.DeletedAt = time.Now().UTC().Format(time.RFC3339)
+	}
+	s.tasks[key] = task
+	return nil
+}
+
+func (s *Storage) DeleteTask(id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	task, key, ok := s.find(id)
+	if !ok || !live(task) {
+		return store.ErrNoSuchTask
+	}
+	task.DeletedAt = time.Now().UTC().Format(time.RFC3339)
+	s.tasks[key] = task
+	return nil
+}
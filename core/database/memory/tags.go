@@ -0,0 +1,158 @@
+package memory
+
+import (
+	"main/core/database/store"
+	"main/internal/models/tags"
+	"main/internal/models/tasks"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Tasks keep the names of their tags, so renaming and merging tags changes
+// the tasks too.
+
+func (s *Storage) Tags() ([]tags.Tag, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var result []tags.Tag
+	for _, tag := range s.tags {
+		if tag.UserID != s.user {
+			continue
+		}
+		tag.Tasks = len(s.list(func(task tasks.Task) bool {
+			return live(task) && slices.Contains(task.Tags, tag.Name)
+		}, nil))
+		result = append(result, tag)
+	}
+	slices.SortFunc(result, func(a, b tags.Tag) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+	return result, nil
+}
+
+func (s *Storage) AddTag(tag tags.Tag) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.findTagByName(tag.Name); ok {
+		return 0, store.ErrTagExists
+	}
+	return s.addTag(tag.Name), nil
+}
+
+func (s *Storage) UpdateTag(tag tags.Tag) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	current, key, ok := s.findTag(tag.ID)
+	if !ok {
+		return store.ErrNoSuchTag
+	}
+	if other, ok := s.findTagByName(tag.Name); ok && other != key {
+		return store.ErrTagExists
+	}
+	s.retag(current.Name, tag.Name)
+	current.Name = tag.Name
+	s.tags[key] = current
+	return nil
+}
+
+func (s *Storage) MergeTags(from string, into string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	source, fromKey, ok := s.findTag(from)
+	if !ok {
+		return store.ErrNoSuchTag
+	}
+	target, intoKey, ok := s.findTag(into)
+	if !ok {
+		return store.ErrNoSuchTag
+	}
+	if fromKey == intoKey {
+		return nil
+	}
+	s.retag(source.Name, target.Name)
+	delete(s.tags, fromKey)
+	return nil
+}
+
+func (s *Storage) DeleteTag(id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	tag, key, ok := s.findTag(id)
+	if !ok {
+		return store.ErrNoSuchTag
+	}
+	s.retag(tag.Name, "")
+	delete(s.tags, key)
+	return nil
+}
+
+// findTag returns a tag of the user by its id.
+func (s *Storage) findTag(id string) (tags.Tag, int64, bool) {
+	key, err := strconv.ParseInt(id, 10, 64)
+	if err != nil {
+		return tags.Tag{}, 0, false
+	}
+	tag, ok := s.tags[key]
+	if !ok || tag.UserID != s.user {
+		return tags.Tag{}, 0, false
+	}
+	return tag, key, true
+}
+
+// findTagByName returns the id of a tag of the user.
+func (s *Storage) findTagByName(name string) (int64, bool) {
+	for key, tag := range s.tags {
+		if tag.UserID == s.user && tag.Name == name {
+			return key, true
+		}
+	}
+	return 0, false
+}
+
+func (s *Storage) addTag(name string) int64 {
+	s.lastTag++
+	s.tags[s.lastTag] = tags.Tag{ID: strconv.FormatInt(s.lastTag, 10), Name: name, UserID: s.user}
+	return s.lastTag
+}
+
+// useTags creates the tags the user has not used yet and returns a copy of
+// names for a task to keep.
+func (s *Storage) useTags(names []string) []string {
+	for _, name := range names {
+		if _, ok := s.findTagByName(name); !ok {
+			s.addTag(name)
+		}
+	}
+	if len(names) == 0 {
+		return nil
+	}
+	return slices.Clone(names)
+}
+
+// retag replaces tag from with tag into on the user's tasks, or removes it if
+// into is empty.
+func (s *Storage) retag(from string, into string) {
+	for key, task := range s.tasks {
+		if task.UserID != s.user || !slices.Contains(task.Tags, from) {
+			continue
+		}
+		names := slices.DeleteFunc(slices.Clone(task.Tags), func(name string) bool {
+			return name == from
+		})
+		if into != "" && !slices.Contains(names, into) {
+			names = append(names, into)
+			slices.Sort(names)
+		}
+		if len(names) == 0 {
+			names = nil
+		}
+		task.Tags = names
+		s.tasks[key] = task
+	}
+}
@@ -0,0 +1,81 @@
+package memory
+
+import (
+	"main/core/database/store"
+	"main/internal/models/tasks"
+	"slices"
+	"time"
+)
+
+func (s *Storage) Trash() ([]tasks.Task, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	result := s.list(func(task tasks.Task) bool {
+		return !live(task)
+	}, func(a, b tasks.Task) bool {
+		return a.DeletedAt < b.DeletedAt
+	})
+	// Most recently deleted first.
+	for i, j := 0, len(result)-1; i < j; i, j = i+1, j-1 {
+		result[i], result[j] = result[j], result[i]
+	}
+	return result, nil
+}
+
+func (s *Storage) RestoreTask(id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	task, key, ok := s.find(id)
+	if !ok || live(task) {
+		return store.ErrNoSuchTask
+	}
+	task.DeletedAt = ""
+	s.tasks[key] = task
+	return nil
+}
+
+func (s *Storage) PurgeTask(id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	task, key, ok := s.find(id)
+	if !ok || live(task) {
+		return store.ErrNoSuchTask
+	}
+	s.purge(key)
+	return nil
+}
+
+func (s *Storage) PurgeTrash(before time.Time) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	limit := before.UTC().Format(time.RFC3339)
+	var n int64
+	for key, task := range s.tasks {
+		if task.UserID != s.user || live(task) || (!before.IsZero() && task.DeletedAt >= limit) {
+			continue
+		}
+		s.purge(key)
+		n++
+	}
+	return n, nil
+}
+
+// purge deletes a task with its checklist and dependencies.
+func (s *Storage) purge(key int64) {
+	delete(s.tasks, key)
+	delete(s.blockers, key)
+	for task, blockers := range s.blockers {
+		s.blockers[task] = slices.DeleteFunc(blockers, func(blocker int64) bool {
+			return blocker == key
+		})
+	}
+	for itemKey, item := range s.items {
+		if item.TaskID == key {
+			delete(s.items, itemKey)
+		}
+	}
+}
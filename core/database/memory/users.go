@@ -0,0 +1,97 @@
+package memory
+
+import (
+	"main/core/database/store"
+	"main/internal/models/users"
+	"sort"
+	"strconv"
+	"time"
+)
+
+func (s *Storage) AddUser(user users.User) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, existing := range s.users {
+		if existing.Login == user.Login {
+			return 0, store.ErrUserExists
+		}
+	}
+	s.lastUser++
+	user.ID = strconv.FormatInt(s.lastUser, 10)
+	user.CreatedAt = time.Now().UTC().Format(time.RFC3339)
+	s.users[s.lastUser] = user
+	return s.lastUser, nil
+}
+
+func (s *Storage) FindUser(id int64) (*users.User, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	user, ok := s.users[id]
+	if !ok {
+		return nil, store.ErrNoSuchUser
+	}
+	return &user, nil
+}
+
+func (s *Storage) FindUserByLogin(login string) (*users.User, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, user := range s.users {
+		if user.Login == login {
+			return &user, nil
+		}
+	}
+	return nil, store.ErrNoSuchUser
+}
+
+func (s *Storage) Users() ([]users.User, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var result []users.User
+	for _, user := range s.users {
+		result = append(result, user)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		a, _ := strconv.ParseInt(result[i].ID, 10, 64)
+		b, _ := strconv.ParseInt(result[j].ID, 10, 64)
+		return a < b
+	})
+	return result, nil
+}
+
+func (s *Storage) DeleteUser(id int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.users[id]; !ok {
+		return store.ErrNoSuchUser
+	}
+	delete(s.users, id)
+	for key, task := range s.tasks {
+		if task.UserID == id {
+			s.purge(key)
+		}
+	}
+	kept := s.completions[:0]
+	for _, completion := range s.completions {
+		if completion.UserID != id {
+			kept = append(kept, completion)
+		}
+	}
+	s.completions = kept
+	for key, list := range s.lists {
+		if list.UserID == id {
+			delete(s.lists, key)
+		}
+	}
+	for key, tag := range s.tags {
+		if tag.UserID == id {
+			delete(s.tags, key)
+		}
+	}
+	return nil
+}
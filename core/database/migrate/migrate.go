@@ -0,0 +1,251 @@
+// Package migrate applies numbered schema migrations and records them in the
+// schema_migrations table.
+//
+// Migrations are read from pairs of files named like 0001_create_tasks.up.sql
+// and 0001_create_tasks.down.sql. Each migration runs in its own transaction.
+package migrate
+
+import (
+	"database/sql"
+	"fmt"
+	"io/fs"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Migrator applies migrations to a database.
+type Migrator struct {
+	DB         *sql.DB
+	Migrations []Migration
+	// Numbered makes queries use $1, $2 placeholders instead of ?, as
+	// PostgreSQL requires.
+	Numbered bool
+}
+
+type Migration struct {
+	Version int
+	Name    string
+	Up      string
+	Down    string
+}
+
+// State is a migration together with the time it was applied, which is zero
+// for pending migrations.
+type State struct {
+	Migration
+	AppliedAt string
+}
+
+// Load reads migrations from the root of fsys, sorted by version.
+func Load(fsys fs.FS) ([]Migration, error) {
+	files, err := fs.Glob(fsys, "*.sql")
+	if err != nil {
+		return nil, err
+	}
+
+	byVersion := make(map[int]*Migration)
+	for _, file := range files {
+		name, direction, ok := strings.Cut(strings.TrimSuffix(path.Base(file), ".sql"), ".")
+		if !ok || (direction != "up" && direction != "down") {
+			return nil, fmt.Errorf("migration %s: name must end with .up.sql or .down.sql", file)
+		}
+		number, title, _ := strings.Cut(name, "_")
+		version, err := strconv.Atoi(number)
+		if err != nil || version < 1 {
+			return nil, fmt.Errorf("migration %s: name must start with a positive version number", file)
+		}
+		data, err := fs.ReadFile(fsys, file)
+		if err != nil {
+			return nil, err
+		}
+
+		migration := byVersion[version]
+		if migration == nil {
+			migration = &Migration{Version: version, Name: title}
+			byVersion[version] = migration
+		} else if migration.Name != title {
+			return nil, fmt.Errorf("migration %d has different names: %s and %s", version, migration.Name, title)
+		}
+		if direction == "up" {
+			migration.Up = string(data)
+		} else {
+			migration.Down = string(data)
+		}
+	}
+
+	result := make([]Migration, 0, len(byVersion))
+	for _, migration := range byVersion {
+		if migration.Up == "" {
+			return nil, fmt.Errorf("migration %d has no up script", migration.Version)
+		}
+		result = append(result, *migration)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Version < result[j].Version
+	})
+	return result, nil
+}
+
+// query rewrites ? placeholders into the style the database expects.
+func (m Migrator) query(query string) string {
+	if !m.Numbered {
+		return query
+	}
+	var b strings.Builder
+	n := 0
+	for _, r := range query {
+		if r == '?' {
+			n++
+			b.WriteString("$" + strconv.Itoa(n))
+			continue
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+func (m Migrator) ensureTable() error {
+	_, err := m.DB.Exec(`
+		CREATE TABLE IF NOT EXISTS schema_migrations (
+		   version INTEGER PRIMARY KEY,
+		   name TEXT NOT NULL,
+		   applied_at VARCHAR(20) NOT NULL
+		)`)
+	return err
+}
+
+func (m Migrator) applied() (map[int]string, error) {
+	if err := m.ensureTable(); err != nil {
+		return nil, err
+	}
+	rows, err := m.DB.Query("SELECT version, applied_at FROM schema_migrations")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	result := make(map[int]string)
+	for rows.Next() {
+		var version int
+		var appliedAt string
+		if err = rows.Scan(&version, &appliedAt); err != nil {
+			return nil, err
+		}
+		result[version] = appliedAt
+	}
+	return result, rows.Err()
+}
+
+// Status returns every known migration and whether it has been applied.
+func (m Migrator) Status() ([]State, error) {
+	done, err := m.applied()
+	if err != nil {
+		return nil, err
+	}
+	result := make([]State, len(m.Migrations))
+	for i, migration := range m.Migrations {
+		result[i] = State{Migration: migration, AppliedAt: done[migration.Version]}
+	}
+	return result, nil
+}
+
+// Version returns the latest applied migration version, or 0 for an empty
+// database.
+func (m Migrator) Version() (int, error) {
+	if err := m.ensureTable(); err != nil {
+		return 0, err
+	}
+	var version sql.NullInt64
+	err := m.DB.QueryRow("SELECT max(version) FROM schema_migrations").Scan(&version)
+	return int(version.Int64), err
+}
+
+// Up applies pending migrations in order and returns how many were applied.
+func (m Migrator) Up() (int, error) {
+	done, err := m.applied()
+	if err != nil {
+		return 0, err
+	}
+
+	count := 0
+	for _, migration := range m.Migrations {
+		if _, ok := done[migration.Version]; ok {
+			continue
+		}
+		err = m.run(migration.Up, func(tx *sql.Tx) error {
+			_, err := tx.Exec(m.query("INSERT INTO schema_migrations (version, name, applied_at) VALUES (?, ?, ?)"),
+				migration.Version, migration.Name, time.Now().UTC().Format(time.RFC3339))
+			return err
+		})
+		if err != nil {
+			return count, fmt.Errorf("migration %d %s: %w", migration.Version, migration.Name, err)
+		}
+		count++
+	}
+	return count, nil
+}
+
+// Down reverts up to steps of the latest applied migrations and returns how
+// many were reverted.
+func (m Migrator) Down(steps int) (int, error) {
+	done, err := m.applied()
+	if err != nil {
+		return 0, err
+	}
+
+	count := 0
+	for i := len(m.Migrations) - 1; i >= 0 && count < steps; i-- {
+		migration := m.Migrations[i]
+		if _, ok := done[migration.Version]; !ok {
+			continue
+		}
+		if migration.Down == "" {
+			return count, fmt.Errorf("migration %d %s cannot be reverted", migration.Version, migration.Name)
+		}
+		err = m.run(migration.Down, func(tx *sql.Tx) error {
+			_, err := tx.Exec(m.query("DELETE FROM schema_migrations WHERE version = ?"), migration.Version)
+			return err
+		})
+		if err != nil {
+			return count, fmt.Errorf("migration %d %s: %w", migration.Version, migration.Name, err)
+		}
+		count++
+	}
+	return count, nil
+}
+
+// Check returns an error if the database has migrations applied that are newer
+// than any known one, which means it was migrated by a newer build.
+func (m Migrator) Check() error {
+	version, err := m.Version()
+	if err != nil {
+		return fmt.Errorf("failed to read schema version: %w", err)
+	}
+	latest := 0
+	if len(m.Migrations) > 0 {
+		latest = m.Migrations[len(m.Migrations)-1].Version
+	}
+	if version > latest {
+		return fmt.Errorf("database schema version %d is newer than %d supported by this build", version, latest)
+	}
+	return nil
+}
+
+func (m Migrator) run(script string, record func(tx *sql.Tx) error) error {
+	tx, err := m.DB.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err = tx.Exec(script); err != nil {
+		return err
+	}
+	if err = record(tx); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
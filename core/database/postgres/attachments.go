@@ -0,0 +1,101 @@
+package postgres
+
+import (
+	"database/sql"
+	"errors"
+	"main/core/database/store"
+	"main/internal/models/attachments"
+	"strconv"
+	"time"
+)
+
+const attachmentColumns = "id, task_id, name, size, content_type, file, created_at"
+
+// ownAttachment selects attachments of the user's live tasks. It takes the
+// user as parameter $2.
+const ownAttachment = "task_id IN (SELECT id FROM scheduler WHERE user_id = $2 AND deleted_at = '')"
+
+func (s *Storage) Attachments(task string) ([]attachments.Attachment, error) {
+	key, err := taskID(task)
+	if err != nil {
+		return nil, err
+	}
+	if err = checkTask(s.db, s.user, key); err != nil {
+		return nil, err
+	}
+	return scanAttachments(s.db.Query("SELECT "+attachmentColumns+" FROM attachments WHERE task_id = $1 ORDER BY id", key))
+}
+
+func (s *Storage) AddAttachment(attachment attachments.Attachment) (int64, error) {
+	var id int64
+	err := s.db.QueryRow(`INSERT INTO attachments (task_id, name, size, content_type, file, created_at)
+		SELECT id, $3, $4, $5, $6, $7 FROM scheduler WHERE id = $1 AND user_id = $2 AND deleted_at = '' RETURNING id`,
+		attachment.TaskID, s.user, attachment.Name, attachment.Size, attachment.ContentType, attachment.File,
+		time.Now().UTC().Format(time.RFC3339)).Scan(&id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, store.ErrNoSuchTask
+	}
+	return id, err
+}
+
+func (s *Storage) FindAttachment(id string) (*attachments.Attachment, error) {
+	key, err := strconv.ParseInt(id, 10, 64)
+	if err != nil {
+		return nil, store.ErrNoSuchAttachment
+	}
+	var attachment attachments.Attachment
+	err = s.db.QueryRow("SELECT "+attachmentColumns+" FROM attachments WHERE id = $1 AND "+ownAttachment, key, s.user).Scan(
+		&attachment.ID, &attachment.TaskID, &attachment.Name, &attachment.Size,
+		&attachment.ContentType, &attachment.File, &attachment.CreatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, store.ErrNoSuchAttachment
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &attachment, nil
+}
+
+func (s *Storage) DeleteAttachment(id string) error {
+	key, err := strconv.ParseInt(id, 10, 64)
+	if err != nil {
+		return store.ErrNoSuchAttachment
+	}
+	result, err := s.db.Exec("DELETE FROM attachments WHERE id = $1 AND "+ownAttachment, key, s.user)
+	if err != nil {
+		return err
+	}
+	if n, err := result.RowsAffected(); err == nil && n == 0 {
+		return store.ErrNoSuchAttachment
+	}
+	return nil
+}
+
+func (s *Storage) PurgeAttachments() ([]attachments.Attachment, error) {
+	return scanAttachments(s.db.Query("DELETE FROM attachments WHERE task_id NOT IN (SELECT id FROM scheduler) RETURNING " + attachmentColumns))
+}
+
+// scanAttachments reads the attachments returned by a query.
+func scanAttachments(rows *sql.Rows, err error) ([]attachments.Attachment, error) {
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var result []attachments.Attachment
+	for rows.Next() {
+		var attachment attachments.Attachment
+		err = rows.Scan(&attachment.ID, &attachment.TaskID, &attachment.Name, &attachment.Size,
+			&attachment.ContentType, &attachment.File, &attachment.CreatedAt)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, attachment)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
@@ -0,0 +1,140 @@
+package postgres
+
+import (
+	"database/sql"
+	"errors"
+	"main/core/database/store"
+	"main/internal/models/checklists"
+	"strconv"
+)
+
+// ownItem selects checklist items of the user's live tasks. It takes the user
+// as parameter $2.
+const ownItem = "task_id IN (SELECT id FROM scheduler WHERE user_id = $2 AND deleted_at = '')"
+
+// checkTask returns store.ErrNoSuchTask unless the user has the task and it is
+// not in the trash. In a transaction, it locks the task until the end.
+func checkTask(q querier, user int64, task int64) error {
+	var id int64
+	err := q.QueryRow("SELECT id FROM scheduler WHERE id = $1 AND user_id = $2 AND deleted_at = '' FOR UPDATE", task, user).Scan(&id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return store.ErrNoSuchTask
+	}
+	return err
+}
+
+func (s *Storage) Checklist(task string) ([]checklists.Item, error) {
+	key, err := taskID(task)
+	if err != nil {
+		return nil, err
+	}
+	if err = checkTask(s.db, s.user, key); err != nil {
+		return nil, err
+	}
+	return checklist(s.db, key)
+}
+
+// checklist returns the items of a task in order.
+func checklist(q querier, task int64) ([]checklists.Item, error) {
+	rows, err := q.Query("SELECT id, task_id, position, text, done FROM checklist_items WHERE task_id = $1 ORDER BY position, id", task)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var result []checklists.Item
+	for rows.Next() {
+		var item checklists.Item
+		if err = rows.Scan(&item.ID, &item.TaskID, &item.Position, &item.Text, &item.Done); err != nil {
+			return nil, err
+		}
+		result = append(result, item)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+func (s *Storage) AddItem(task string, text string) (int64, error) {
+	key, err := taskID(task)
+	if err != nil {
+		return 0, err
+	}
+	tx, err := s.db.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	if err = checkTask(tx, s.user, key); err != nil {
+		return 0, err
+	}
+	var id int64
+	err = tx.QueryRow(`INSERT INTO checklist_items (task_id, position, text)
+		SELECT $1, coalesce(max(position), 0) + 1, $2 FROM checklist_items WHERE task_id = $1 RETURNING id`, key, text).Scan(&id)
+	if err != nil {
+		return 0, err
+	}
+	return id, tx.Commit()
+}
+
+func (s *Storage) ReorderChecklist(task string, ids []string) error {
+	key, err := taskID(task)
+	if err != nil {
+		return err
+	}
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err = checkTask(tx, s.user, key); err != nil {
+		return err
+	}
+	items, err := checklist(tx, key)
+	if err != nil {
+		return err
+	}
+	if err = store.CheckOrder(items, ids); err != nil {
+		return err
+	}
+	for i, id := range ids {
+		if _, err = tx.Exec("UPDATE checklist_items SET position = $1 WHERE id = $2", i+1, id); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+func (s *Storage) ToggleItem(id string) (bool, error) {
+	key, err := strconv.ParseInt(id, 10, 64)
+	if err != nil {
+		return false, store.ErrNoSuchItem
+	}
+	var done bool
+	err = s.db.QueryRow("UPDATE checklist_items SET done = NOT done WHERE id = $1 AND "+ownItem+" RETURNING done",
+		key, s.user).Scan(&done)
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, store.ErrNoSuchItem
+	}
+	return done, err
+}
+
+func (s *Storage) DeleteItem(id string) error {
+	key, err := strconv.ParseInt(id, 10, 64)
+	if err != nil {
+		return store.ErrNoSuchItem
+	}
+	result, err := s.db.Exec("DELETE FROM checklist_items WHERE id = $1 AND "+ownItem, key, s.user)
+	if err != nil {
+		return err
+	}
+	if n, err := result.RowsAffected(); err == nil && n == 0 {
+		return store.ErrNoSuchItem
+	}
+	return nil
+}
@@ -0,0 +1,65 @@
+package postgres
+
+import (
+	"database/sql"
+	"main/internal/models/completions"
+	"strconv"
+	"time"
+)
+
+const completionColumns = "id, task_id, title, date, completed_at, note"
+
+// addCompletion records that a task scheduled on date was done. Completion
+// times are stored in UTC so that they compare correctly as strings.
+func addCompletion(tx execer, user int64, task int64, title string, date string, note string) error {
+	_, err := tx.Exec("INSERT INTO task_completions (user_id, task_id, title, date, completed_at, note) VALUES ($1, $2, $3, $4, $5, $6)",
+		user, task, title, date, time.Now().UTC().Format(time.RFC3339), note)
+	return err
+}
+
+func (s *Storage) TaskHistory(id string) ([]completions.Completion, error) {
+	key, err := strconv.ParseInt(id, 10, 64)
+	if err != nil {
+		return nil, nil
+	}
+	query := "SELECT " + completionColumns + " FROM task_completions WHERE task_id = $1 AND user_id = $2 ORDER BY completed_at, id"
+	rows, err := s.db.Query(query, key, s.user)
+	if err != nil {
+		return nil, err
+	}
+	return scanCompletions(rows)
+}
+
+func (s *Storage) Completions(from time.Time, to time.Time) ([]completions.Completion, error) {
+	query := "SELECT " + completionColumns + " FROM task_completions WHERE user_id = $1 AND completed_at >= $2"
+	args := []any{s.user, from.UTC().Format(time.RFC3339)}
+	if !to.IsZero() {
+		query += " AND completed_at < $3"
+		args = append(args, to.UTC().Format(time.RFC3339))
+	}
+	rows, err := s.db.Query(query+" ORDER BY completed_at, id", args...)
+	if err != nil {
+		return nil, err
+	}
+	return scanCompletions(rows)
+}
+
+func scanCompletions(rows *sql.Rows) ([]completions.Completion, error) {
+	defer rows.Close()
+
+	var result []completions.Completion
+	for rows.Next() {
+		var completion completions.Completion
+		if err := rows.Scan(&completion.ID, &completion.TaskID, &completion.Title, &completion.Date,
+			&completion.CompletedAt, &completion.Note); err != nil {
+			return nil, err
+		}
+		result = append(result, completion)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
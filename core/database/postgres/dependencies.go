@@ -0,0 +1,150 @@
+package postgres
+
+import (
+	"database/sql"
+	"github.com/lib/pq"
+	"main/core/database/store"
+	"main/internal/models/tasks"
+	"strconv"
+)
+
+// openBlocker is a condition on a dependency of task t on blocker b that
+// holds while the blocker is open: a one-off blocker until it is in the
+// trash, and a repeating one until it moves past the date of the task.
+const openBlocker = "b.deleted_at = '' AND (b.repeat = '' OR b.date <= t.date)"
+
+// scanDetailed reads tasks selected with taskColumns along with their tags
+// and whether they are blocked.
+func (s *Storage) scanDetailed(rows *sql.Rows) ([]tasks.Task, error) {
+	list, err := scanTasks(rows)
+	if err != nil {
+		return nil, err
+	}
+	if err = s.loadDetails(list); err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+// loadDetails sets the tags of the tasks in list and whether they are
+// blocked.
+func (s *Storage) loadDetails(list []tasks.Task) error {
+	if err := s.loadTags(list); err != nil {
+		return err
+	}
+	return s.loadBlocked(s.db, list)
+}
+
+// loadBlocked sets Blocked for the tasks in list that have open blockers.
+func (s *Storage) loadBlocked(q querier, list []tasks.Task) error {
+	if len(list) == 0 {
+		return nil
+	}
+	ids := make([]int64, len(list))
+	for i, task := range list {
+		ids[i], _ = strconv.ParseInt(task.ID, 10, 64)
+	}
+	rows, err := q.Query(`SELECT DISTINCT d.task_id FROM task_dependencies d
+		JOIN scheduler t ON t.id = d.task_id JOIN scheduler b ON b.id = d.blocker_id
+		WHERE d.task_id = ANY($1) AND `+openBlocker, pq.Array(ids))
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	blocked := make(map[string]bool)
+	for rows.Next() {
+		var id string
+		if err = rows.Scan(&id); err != nil {
+			return err
+		}
+		blocked[id] = true
+	}
+	if err = rows.Err(); err != nil {
+		return err
+	}
+
+	for i := range list {
+		list[i].Blocked = blocked[list[i].ID]
+	}
+	return nil
+}
+
+func (s *Storage) Blockers(id string) ([]tasks.Task, error) {
+	key, err := taskID(id)
+	if err != nil {
+		return nil, err
+	}
+	if err = checkTask(s.db, s.user, key); err != nil {
+		return nil, err
+	}
+	rows, err := s.db.Query("SELECT "+taskColumns+` FROM scheduler
+		WHERE id IN (SELECT blocker_id FROM task_dependencies WHERE task_id = $1) AND user_id = $2 AND deleted_at = ''
+		ORDER BY date, priority, time, id`, key, s.user)
+	if err != nil {
+		return nil, err
+	}
+	return s.scanDetailed(rows)
+}
+
+func (s *Storage) AddDependency(id string, blocker string) error {
+	task, err := taskID(id)
+	if err != nil {
+		return err
+	}
+	by, err := taskID(blocker)
+	if err != nil {
+		return err
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	// Both tasks are locked, so that concurrent links can't make a cycle.
+	for _, key := range []int64{task, by} {
+		if err = checkTask(tx, s.user, key); err != nil {
+			return err
+		}
+	}
+	// The blocker must not be blocked by the task through a chain of
+	// dependencies.
+	var n int
+	err = tx.QueryRow(`WITH RECURSIVE chain (id) AS (
+			SELECT blocker_id FROM task_dependencies WHERE task_id = $1
+			UNION SELECT d.blocker_id FROM task_dependencies d JOIN chain c ON d.task_id = c.id
+		) SELECT count(*) FROM chain WHERE id = $2`, by, task).Scan(&n)
+	if err != nil {
+		return err
+	}
+	if n > 0 || task == by {
+		return store.ErrDependencyCycle
+	}
+	_, err = tx.Exec("INSERT INTO task_dependencies (task_id, blocker_id) VALUES ($1, $2) ON CONFLICT DO NOTHING", task, by)
+	if err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (s *Storage) DeleteDependency(id string, blocker string) error {
+	task, err := taskID(id)
+	if err != nil {
+		return err
+	}
+	by, err := taskID(blocker)
+	if err != nil {
+		return err
+	}
+	result, err := s.db.Exec(`DELETE FROM task_dependencies WHERE task_id = $1 AND blocker_id = $2
+		AND task_id IN (SELECT id FROM scheduler WHERE user_id = $3)`, task, by, s.user)
+	if err != nil {
+		return err
+	}
+	if n, err := result.RowsAffected(); err == nil && n == 0 {
+		return store.ErrNoSuchDependency
+	}
+	return nil
+}
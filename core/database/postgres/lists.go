@@ -0,0 +1,130 @@
+package postgres
+
+import (
+	"errors"
+	"github.com/lib/pq"
+	"main/core/database/store"
+	"main/internal/models/lists"
+	"strconv"
+)
+
+// isUnique reports whether err is a violation of a UNIQUE constraint.
+func isUnique(err error) bool {
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && pqErr.Code == "23505"
+}
+
+func (s *Storage) Lists() ([]lists.List, error) {
+	rows, err := s.db.Query(`SELECT l.id, l.name, count(s.id) FROM lists l
+		LEFT JOIN scheduler s ON s.list_id = l.id AND s.user_id = l.user_id AND s.deleted_at = ''
+		WHERE l.user_id = $1 GROUP BY l.id ORDER BY l.name, l.id`, s.user)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var result []lists.List
+	for rows.Next() {
+		var list lists.List
+		if err = rows.Scan(&list.ID, &list.Name, &list.Tasks); err != nil {
+			return nil, err
+		}
+		result = append(result, list)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+func (s *Storage) AddList(list lists.List) (int64, error) {
+	var id int64
+	err := s.db.QueryRow("INSERT INTO lists (user_id, name) VALUES ($1, $2) RETURNING id", s.user, list.Name).Scan(&id)
+	if isUnique(err) {
+		return 0, store.ErrListExists
+	}
+	if err != nil {
+		return 0, err
+	}
+	return id, nil
+}
+
+func (s *Storage) UpdateList(list lists.List) error {
+	key, err := strconv.ParseInt(list.ID, 10, 64)
+	if err != nil {
+		return store.ErrNoSuchList
+	}
+	result, err := s.db.Exec("UPDATE lists SET name = $1 WHERE id = $2 AND user_id = $3", list.Name, key, s.user)
+	if isUnique(err) {
+		return store.ErrListExists
+	}
+	if err != nil {
+		return err
+	}
+	if n, err := result.RowsAffected(); err == nil && n == 0 {
+		return store.ErrNoSuchList
+	}
+	return nil
+}
+
+// DeleteList deletes a list, leaving its tasks out of any list.
+func (s *Storage) DeleteList(id string) error {
+	key, err := strconv.ParseInt(id, 10, 64)
+	if err != nil {
+		return store.ErrNoSuchList
+	}
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	result, err := tx.Exec("DELETE FROM lists WHERE id = $1 AND user_id = $2", key, s.user)
+	if err != nil {
+		return err
+	}
+	if n, err := result.RowsAffected(); err == nil && n == 0 {
+		return store.ErrNoSuchList
+	}
+	if _, err = tx.Exec("UPDATE scheduler SET list_id = 0 WHERE list_id = $1 AND user_id = $2", key, s.user); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// checkList returns store.ErrNoSuchList unless the user has the list or
+// list is zero.
+func (s *Storage) checkList(list int64) error {
+	if list == 0 {
+		return nil
+	}
+	var n int
+	if err := s.db.QueryRow("SELECT count(*) FROM lists WHERE id = $1 AND user_id = $2", list, s.user).Scan(&n); err != nil {
+		return err
+	}
+	if n == 0 {
+		return store.ErrNoSuchList
+	}
+	return nil
+}
+
+// MoveTask moves a task to a list, or out of any list if list is zero.
+func (s *Storage) MoveTask(id string, list int64) error {
+	key, err := taskID(id)
+	if err != nil {
+		return err
+	}
+	if err = s.checkList(list); err != nil {
+		return err
+	}
+	result, err := s.db.Exec("UPDATE scheduler SET list_id = $1 WHERE id = $2 AND user_id = $3 AND deleted_at = ''", list, key, s.user)
+	if err != nil {
+		return err
+	}
+	if n, err := result.RowsAffected(); err == nil && n == 0 {
+		return store.ErrNoSuchTask
+	}
+	return nil
+}
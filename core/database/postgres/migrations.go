@@ -0,0 +1,80 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"embed"
+	"fmt"
+	"go.uber.org/zap"
+	"io/fs"
+	"main/core/database/migrate"
+	"main/core/logger"
+)
+
+// migrationLock is the advisory lock key that keeps instances started at the
+// same time from migrating the database together.
+const migrationLock = 7540
+
+//go:embed migrations/*.sql
+var migrationFiles embed.FS
+
+// Migrations returns the schema migrations of the PostgreSQL database.
+func Migrations() ([]migrate.Migration, error) {
+	fsys, err := fs.Sub(migrationFiles, "migrations")
+	if err != nil {
+		return nil, err
+	}
+	return migrate.Load(fsys)
+}
+
+// Open connects to the database given by dsn without migrating it.
+func Open(dsn string) (*sql.DB, error) {
+	db, err := sql.Open(dbDriver, dsn)
+	if err != nil {
+		return nil, err
+	}
+	if err = db.Ping(); err != nil {
+		db.Close()
+		return nil, err
+	}
+	return db, nil
+}
+
+// Migrator returns the migrator of the database.
+func Migrator(db *sql.DB) (migrate.Migrator, error) {
+	migrations, err := Migrations()
+	if err != nil {
+		return migrate.Migrator{}, fmt.Errorf("failed to load migrations: %w", err)
+	}
+	return migrate.Migrator{DB: db, Migrations: migrations, Numbered: true}, nil
+}
+
+func migrateDB(db *sql.DB) error {
+	migrator, err := Migrator(db)
+	if err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	if _, err = conn.ExecContext(ctx, "SELECT pg_advisory_lock($1)", migrationLock); err != nil {
+		return fmt.Errorf("failed to lock database for migration: %w", err)
+	}
+	defer conn.ExecContext(ctx, "SELECT pg_advisory_unlock($1)", migrationLock)
+
+	if err = migrator.Check(); err != nil {
+		return fmt.Errorf("cannot use database: %w", err)
+	}
+	n, err := migrator.Up()
+	if err != nil {
+		return fmt.Errorf("failed to migrate database: %w", err)
+	}
+	if n > 0 {
+		logger.Get().Info("database migrated", zap.Int("migrations", n))
+	}
+	return nil
+}
@@ -0,0 +1,3 @@
+DROP TABLE task_completions;
+DROP TABLE holidays;
+DROP TABLE scheduler;
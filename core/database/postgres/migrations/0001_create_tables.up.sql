@@ -0,0 +1,31 @@
+CREATE TABLE scheduler (
+   id BIGSERIAL PRIMARY KEY,
+   date VARCHAR(8) NOT NULL,
+   time VARCHAR(4) NOT NULL DEFAULT '',
+   title TEXT NOT NULL,
+   comment TEXT NOT NULL DEFAULT '',
+   repeat VARCHAR(128) NOT NULL,
+   occurrence INTEGER NOT NULL DEFAULT 1,
+   shift VARCHAR(4) NOT NULL DEFAULT '',
+   anchor VARCHAR(8) NOT NULL DEFAULT '',
+   deleted_at VARCHAR(20) NOT NULL DEFAULT ''
+);
+
+CREATE INDEX scheduler_date ON scheduler (date);
+
+CREATE TABLE holidays (
+   date VARCHAR(8) PRIMARY KEY,
+   name TEXT NOT NULL DEFAULT ''
+);
+
+CREATE TABLE task_completions (
+   id BIGSERIAL PRIMARY KEY,
+   task_id BIGINT NOT NULL,
+   title TEXT NOT NULL,
+   date VARCHAR(8) NOT NULL,
+   completed_at VARCHAR(20) NOT NULL,
+   note TEXT NOT NULL DEFAULT ''
+);
+
+CREATE INDEX task_completions_task ON task_completions (task_id, completed_at);
+CREATE INDEX task_completions_completed ON task_completions (completed_at);
@@ -0,0 +1,2 @@
+DROP INDEX scheduler_search;
+ALTER TABLE scheduler DROP COLUMN search;
@@ -0,0 +1,5 @@
+ALTER TABLE scheduler ADD COLUMN search tsvector GENERATED ALWAYS AS (
+   setweight(to_tsvector('simple', title), 'A') || setweight(to_tsvector('simple', comment), 'B')
+) STORED;
+
+CREATE INDEX scheduler_search ON scheduler USING GIN (search);
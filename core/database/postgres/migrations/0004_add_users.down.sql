@@ -0,0 +1,14 @@
+DROP INDEX task_completions_completed;
+CREATE INDEX task_completions_completed ON task_completions (completed_at);
+
+DROP INDEX scheduler_list_date;
+DROP INDEX scheduler_list_title;
+DROP INDEX scheduler_list_id;
+CREATE INDEX scheduler_list_date ON scheduler (deleted_at, date, time, id);
+CREATE INDEX scheduler_list_title ON scheduler (deleted_at, title, id);
+CREATE INDEX scheduler_list_id ON scheduler (deleted_at, id);
+
+ALTER TABLE task_completions DROP COLUMN user_id;
+ALTER TABLE scheduler DROP COLUMN user_id;
+
+DROP TABLE users;
@@ -0,0 +1,4 @@
+DROP INDEX scheduler_in_list;
+ALTER TABLE scheduler DROP COLUMN list_id;
+
+DROP TABLE lists;
@@ -0,0 +1,9 @@
+CREATE TABLE lists (
+   id BIGSERIAL PRIMARY KEY,
+   user_id BIGINT NOT NULL DEFAULT 0,
+   name VARCHAR(64) NOT NULL,
+   UNIQUE (user_id, name)
+);
+
+ALTER TABLE scheduler ADD COLUMN list_id BIGINT NOT NULL DEFAULT 0;
+CREATE INDEX scheduler_in_list ON scheduler (user_id, list_id, deleted_at);
@@ -0,0 +1,13 @@
+CREATE TABLE tags (
+   id BIGSERIAL PRIMARY KEY,
+   user_id BIGINT NOT NULL DEFAULT 0,
+   name VARCHAR(32) NOT NULL,
+   UNIQUE (user_id, name)
+);
+
+CREATE TABLE task_tags (
+   task_id BIGINT NOT NULL REFERENCES scheduler (id) ON DELETE CASCADE,
+   tag_id BIGINT NOT NULL REFERENCES tags (id) ON DELETE CASCADE,
+   PRIMARY KEY (task_id, tag_id)
+);
+CREATE INDEX task_tags_tag ON task_tags (tag_id);
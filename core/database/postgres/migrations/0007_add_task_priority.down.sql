@@ -0,0 +1,4 @@
+DROP INDEX scheduler_list_date;
+CREATE INDEX scheduler_list_date ON scheduler (user_id, deleted_at, date, time, id);
+
+ALTER TABLE scheduler DROP COLUMN priority;
@@ -0,0 +1,4 @@
+ALTER TABLE scheduler ADD COLUMN priority INTEGER NOT NULL DEFAULT 4;
+
+DROP INDEX scheduler_list_date;
+CREATE INDEX scheduler_list_date ON scheduler (user_id, deleted_at, date, priority, time, id);
@@ -0,0 +1,8 @@
+CREATE TABLE checklist_items (
+   id BIGSERIAL PRIMARY KEY,
+   task_id BIGINT NOT NULL REFERENCES scheduler (id) ON DELETE CASCADE,
+   position INTEGER NOT NULL,
+   text VARCHAR(256) NOT NULL,
+   done BOOLEAN NOT NULL DEFAULT FALSE
+);
+CREATE INDEX checklist_items_task ON checklist_items (task_id, position);
@@ -0,0 +1,13 @@
+-- Attachments outlive their tasks until the store is asked to purge them, so
+-- that their files can be removed from disk first. That is why task_id is not
+-- a foreign key.
+CREATE TABLE attachments (
+   id BIGSERIAL PRIMARY KEY,
+   task_id BIGINT NOT NULL,
+   name VARCHAR(255) NOT NULL,
+   size BIGINT NOT NULL,
+   content_type VARCHAR(255) NOT NULL,
+   file VARCHAR(64) NOT NULL,
+   created_at VARCHAR(32) NOT NULL
+);
+CREATE INDEX attachments_task ON attachments (task_id);
@@ -0,0 +1,399 @@
+// Package postgres is a storage backend for PostgreSQL, which unlike SQLite
+// can be shared by several API instances.
+package postgres
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	_ "github.com/lib/pq"
+	"go.uber.org/zap"
+	"main/core/database/store"
+	"main/core/logger"
+	"main/internal/models/tasks"
+	"main/pkg"
+	"strconv"
+	"strings"
+)
+
+const dbDriver = "postgres"
+const taskColumns = "id, date, time, title, comment, repeat, occurrence, shift, anchor, deleted_at, list_id, priority"
+
+// Storage keeps tasks in a PostgreSQL database.
+type Storage struct {
+	db *sql.DB
+	// user owns the tasks the storage works with.
+	user int64
+}
+
+var _ store.Store = (*Storage)(nil)
+
+// New connects to the database given by dsn and brings its schema up to date.
+func New(dsn string) (*Storage, error) {
+	db, err := Open(dsn)
+	if err != nil {
+		return nil, fmt.Errorf("cannot open database: %w", err)
+	}
+	if err = migrateDB(db); err != nil {
+		db.Close()
+		return nil, err
+	}
+	return &Storage{db: db}, nil
+}
+
+func (s *Storage) Close() error {
+	return s.db.Close()
+}
+
+func (s *Storage) ForUser(id int64) store.Store {
+	view := *s
+	view.user = id
+	return &view
+}
+
+type scanner interface {
+	Scan(dest ...any) error
+}
+
+type execer interface {
+	Exec(query string, args ...any) (sql.Result, error)
+}
+
+// querier is a database or a transaction.
+type querier interface {
+	Query(query string, args ...any) (*sql.Rows, error)
+	QueryRow(query string, args ...any) *sql.Row
+}
+
+// scanTask reads a row selected with taskColumns.
+func scanTask(row scanner, task *tasks.Task) error {
+	return row.Scan(&task.ID, &task.Date, &task.Time, &task.Title, &task.Comment, &task.Repeat,
+		&task.Occurrence, &task.Shift, &task.Anchor, &task.DeletedAt, &task.ListID, &task.Priority)
+}
+
+func scanTasks(rows *sql.Rows) ([]tasks.Task, error) {
+	defer rows.Close()
+
+	var result []tasks.Task
+	for rows.Next() {
+		var task tasks.Task
+		if err := scanTask(rows, &task); err != nil {
+			return nil, err
+		}
+		result = append(result, task)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// taskID parses a task id. PostgreSQL rejects comparing a bigint column with
+// a string that is not a number, so such ids are caught here instead.
+func taskID(id string) (int64, error) {
+	n, err := strconv.ParseInt(id, 10, 64)
+	if err != nil {
+		return 0, store.ErrNoSuchTask
+	}
+	return n, nil
+}
+
+func (s *Storage) AddTaskDB(task tasks.Task) (int64, error) {
+	if err := s.shiftTask(&task, task.Date); err != nil {
+		return 0, err
+	}
+	if err := s.checkList(task.ListID); err != nil {
+		return 0, err
+	}
+	if task.Priority == 0 {
+		task.Priority = tasks.PriorityLowest
+	}
+	if task.Occurrence == 0 {
+		task.Occurrence = 1
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	var id int64
+	err = tx.QueryRow(`INSERT INTO scheduler (user_id, list_id, priority, date, time, title, comment, repeat, occurrence, shift, anchor)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11) RETURNING id`,
+		s.user, task.ListID, task.Priority, task.Date, task.Time, task.Title, task.Comment, task.Repeat, task.Occurrence, task.Shift, task.Anchor).Scan(&id)
+	if err != nil {
+		return 0, err
+	}
+	if err = setTags(tx, s.user, id, task.Tags); err != nil {
+		return 0, err
+	}
+	return id, tx.Commit()
+}
+
+func (s *Storage) FindTask(id string) (*tasks.Task, error) {
+	task := tasks.Task{}
+	if id == "" {
+		return nil, errors.New("empty task id")
+	}
+	key, err := taskID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	query := "SELECT " + taskColumns + " FROM scheduler WHERE id = $1 AND user_id = $2 AND deleted_at = ''"
+	err = scanTask(s.db.QueryRow(query, key, s.user), &task)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, store.ErrNoSuchTask
+		}
+		logger.Get().Error("failed to query task", zap.Error(err))
+		return nil, err
+	}
+
+	list := []tasks.Task{task}
+	if err = s.loadDetails(list); err != nil {
+		return nil, err
+	}
+	return &list[0], nil
+}
+
+func (s *Storage) UpdateTask(task tasks.Task) error {
+	key, err := taskID(task.ID)
+	if err != nil {
+		return err
+	}
+
+	// A shifted task keeps its original date unless the date itself is changed.
+	base := task.Date
+	if current, err := s.FindTask(task.ID); err == nil && current.Date == task.Date && current.Anchor != "" {
+		base = current.Anchor
+	}
+	if err := s.shiftTask(&task, base); err != nil {
+		return err
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	// A changed repeat rule starts a new series, so its occurrence count is
+	// reset. The priority is kept unless given.
+	query := `UPDATE scheduler SET date = $1, time = $2, title = $3, comment = $4,
+		occurrence = CASE WHEN repeat = $5 THEN occurrence ELSE 1 END, repeat = $5, shift = $6, anchor = $7,
+		priority = CASE WHEN $8 = 0 THEN priority ELSE $8 END
+		WHERE id = $9 AND user_id = $10 AND deleted_at = ''`
+	result, err := tx.Exec(query, task.Date, task.Time, task.Title, task.Comment, task.Repeat,
+		task.Shift, task.Anchor, task.Priority, key, s.user)
+	if err != nil {
+		logger.Get().Error("failed to update task", zap.Error(err))
+		return err
+	}
+	if n, err := result.RowsAffected(); err == nil && n == 0 {
+		return store.ErrNoSuchTask
+	}
+	// Tags are kept unless given.
+	if task.Tags != nil {
+		if err = setTags(tx, s.user, key, task.Tags); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// sortColumns are the columns each sort field orders by.
+var sortColumns = map[string][]string{
+	store.SortDate:  {"date", "priority", "time", "id"},
+	store.SortTitle: {"title", "id"},
+	store.SortID:    {"id"},
+}
+
+// params collects query arguments and returns their placeholders.
+type params []any
+
+func (p *params) add(value any) string {
+	*p = append(*p, value)
+	return "$" + strconv.Itoa(len(*p))
+}
+
+// keyset returns the ORDER BY clause for columns, and the condition that
+// selects rows after the given values of them if there are any.
+func keyset(columns []string, desc bool, after []any, args *params) (string, string) {
+	order, op := strings.Join(columns, ", "), ">"
+	if desc {
+		order, op = strings.Join(columns, " DESC, ")+" DESC", "<"
+	}
+	if after == nil {
+		return order, ""
+	}
+	marks := make([]string, len(after))
+	for i, value := range after {
+		marks[i] = args.add(value)
+	}
+	return order, "(" + strings.Join(columns, ", ") + ") " + op + " (" + strings.Join(marks, ", ") + ")"
+}
+
+// cursorValues returns the values of the sort columns at the cursor.
+func cursorValues(cursor *store.Cursor) []any {
+	if cursor == nil {
+		return nil
+	}
+	values := make([]any, 0, len(cursor.Key)+1)
+	for _, value := range cursor.Key {
+		values = append(values, value)
+	}
+	return append(values, cursor.ID)
+}
+
+func (s *Storage) Tasks(query store.TaskQuery) (store.TaskPage, error) {
+	field := query.SortField()
+	columns, ok := sortColumns[field]
+	if !ok {
+		return store.TaskPage{}, fmt.Errorf("unknown sort field %q", field)
+	}
+	after, err := query.Cursor(field, query.Desc)
+	if err != nil {
+		return store.TaskPage{}, err
+	}
+
+	var args params
+	where := "user_id = " + args.add(s.user) + " AND deleted_at = ''"
+	if query.From != "" {
+		where += " AND date >= " + args.add(query.From)
+	}
+	if query.To != "" {
+		where += " AND date <= " + args.add(query.To)
+	}
+	if query.List != 0 {
+		where += " AND list_id = " + args.add(query.List)
+	}
+	if query.Tag != "" {
+		where += " AND " + taggedWith(s.user, query.Tag, &args)
+	}
+	if query.Priority != 0 {
+		where += " AND priority = " + args.add(query.Priority)
+	}
+	if query.Recurring != nil {
+		if *query.Recurring {
+			where += " AND repeat <> ''"
+		} else {
+			where += " AND repeat = ''"
+		}
+	}
+
+	var total int
+	if err = s.db.QueryRow("SELECT count(*) FROM scheduler WHERE "+where, args...).Scan(&total); err != nil {
+		return store.TaskPage{}, err
+	}
+
+	order, cond := keyset(columns, query.Desc, cursorValues(after), &args)
+	if cond != "" {
+		where += " AND " + cond
+	}
+	rows, err := s.db.Query("SELECT "+taskColumns+" FROM scheduler WHERE "+where+" ORDER BY "+order+
+		" LIMIT "+args.add(query.Size()+1)+" OFFSET "+args.add(query.Offset), args...)
+	if err != nil {
+		return store.TaskPage{}, err
+	}
+	list, err := s.scanDetailed(rows)
+	if err != nil {
+		return store.TaskPage{}, err
+	}
+	return store.NewTaskPage(list, query.Size(), total, field, query.Desc), nil
+}
+
+func (s *Storage) TasksByDate(date string) ([]tasks.Task, error) {
+	query := "SELECT " + taskColumns + " FROM scheduler WHERE date = $1 AND user_id = $2 AND deleted_at = '' ORDER BY priority, time, id"
+	rows, err := s.db.Query(query, date, s.user)
+	if err != nil {
+		return nil, err
+	}
+	return s.scanDetailed(rows)
+}
+
+func (s *Storage) TasksUntil(date string) ([]tasks.Task, error) {
+	query := "SELECT " + taskColumns + " FROM scheduler WHERE date <= $1 AND user_id = $2 AND deleted_at = '' ORDER BY date, priority, time, id"
+	rows, err := s.db.Query(query, date, s.user)
+	if err != nil {
+		return nil, err
+	}
+	return s.scanDetailed(rows)
+}
+
+// DoneTask locks the task row for the length of the transaction, so that two
+// instances marking the same task done at once roll it forward twice rather
+// than computing the same next date.
+func (s *Storage) DoneTask(id string, note string) error {
+	key, err := taskID(id)
+	if err != nil {
+		return err
+	}
+	cal, err := s.Calendar()
+	if err != nil {
+		return err
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var task tasks.Task
+	query := "SELECT " + taskColumns + " FROM scheduler WHERE id = $1 AND user_id = $2 AND deleted_at = '' FOR UPDATE"
+	if err = scanTask(tx.QueryRow(query, key, s.user), &task); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return store.ErrNoSuchTask
+		}
+		return err
+	}
+	list := []tasks.Task{task}
+	if err = s.loadBlocked(tx, list); err != nil {
+		return err
+	}
+	if list[0].Blocked {
+		return store.ErrTaskBlocked
+	}
+
+	next, anchor, occurrence := "", "", 0
+	if task.Repeat != "" {
+		next, anchor, occurrence, err = store.NextOccurrence(task, cal)
+		if err != nil && !errors.Is(err, pkg.ErrRepeatFinished) {
+			return err
+		}
+	}
+
+	if err = addCompletion(tx, s.user, key, task.Title, task.Date, note); err != nil {
+		return err
+	}
+
+	if next != "" {
+		_, err = tx.Exec("UPDATE scheduler SET date = $1, anchor = $2, occurrence = $3 WHERE id = $4",
+			next, anchor, occurrence, key)
+		if err == nil {
+			// The next occurrence starts with its checklist unchecked.
+			_, err = tx.Exec("UPDATE checklist_items SET done = FALSE WHERE task_id = $1", key)
+		}
+	} else {
+		err = trashTask(tx, s.user, key)
+	}
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// DeleteTask moves a task to the trash.
+func (s *Storage) DeleteTask(id string) error {
+	key, err := taskID(id)
+	if err != nil {
+		return err
+	}
+	return trashTask(s.db, s.user, key)
+}
@@ -0,0 +1,153 @@
+package postgres
+
+import (
+	"main/core/database/store"
+	"main/internal/models/tasks"
+	"strconv"
+	"strings"
+)
+
+// SearchTasks returns tasks matching the filter. Tasks matched by text are
+// ranked by relevance, others are ordered by date.
+func (s *Storage) SearchTasks(filter store.Filter, page store.Page) (store.TaskPage, error) {
+	if filter.Empty() {
+		return store.TaskPage{}, nil
+	}
+
+	var args params
+	where := "user_id = " + args.add(s.user) + " AND deleted_at = ''"
+	if filter.After != "" {
+		where += " AND date >= " + args.add(filter.After)
+	}
+	if filter.Before != "" {
+		where += " AND date < " + args.add(filter.Before)
+	}
+	if filter.Repeat != nil {
+		if *filter.Repeat {
+			where += " AND repeat <> ''"
+		} else {
+			where += " AND repeat = ''"
+		}
+	}
+	for _, tag := range filter.Tags {
+		where += " AND " + taggedWith(s.user, tag, &args)
+	}
+	if filter.Priority != 0 {
+		where += " AND priority = " + args.add(filter.Priority)
+	}
+	if excludes := filter.Excludes(); len(excludes) > 0 {
+		where += " AND NOT search @@ to_tsquery('simple', " + args.add(tsQuery(excludes, " | ")) + ")"
+	}
+
+	matches := filter.Matches()
+	if len(matches) == 0 {
+		return s.searchByDate(where, args, page)
+	}
+
+	after, err := page.Cursor(store.SortRank, false)
+	if err != nil {
+		return store.TaskPage{}, err
+	}
+	from := "FROM scheduler, to_tsquery('simple', " + args.add(tsQuery(matches, " & ")) + ") AS q (query) WHERE " +
+		where + " AND search @@ q.query"
+	var total int
+	if err = s.db.QueryRow("SELECT count(*) "+from, args...).Scan(&total); err != nil {
+		return store.TaskPage{}, err
+	}
+
+	// The score is the rank negated, so that it sorts in the same direction
+	// as id, and widened to keep its exact value in cursors.
+	query := "SELECT * FROM (SELECT " + taskColumns + ", ts_headline('simple', title || ' ' || comment, q.query, " +
+		args.add(headlineOptions) + "), -ts_rank(search, q.query)::float8 AS score " + from + ") AS found"
+	if after != nil {
+		score, err := strconv.ParseFloat(after.Key[0], 64)
+		if err != nil {
+			return store.TaskPage{}, store.ErrInvalidCursor
+		}
+		query += " WHERE (score, id) > (" + args.add(score) + ", " + args.add(after.ID) + ")"
+	}
+	query += " ORDER BY score, id LIMIT " + args.add(page.Size()+1) + " OFFSET " + args.add(page.Offset)
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		return store.TaskPage{}, err
+	}
+	defer rows.Close()
+
+	var list []tasks.Task
+	var scores []float64
+	for rows.Next() {
+		var task tasks.Task
+		var score float64
+		if err = rows.Scan(&task.ID, &task.Date, &task.Time, &task.Title, &task.Comment, &task.Repeat,
+			&task.Occurrence, &task.Shift, &task.Anchor, &task.DeletedAt, &task.ListID, &task.Priority, &task.Snippet, &score); err != nil {
+			return store.TaskPage{}, err
+		}
+		task.Snippet = store.Highlight(task.Snippet)
+		list = append(list, task)
+		scores = append(scores, score)
+	}
+
+	if err = rows.Err(); err != nil {
+		return store.TaskPage{}, err
+	}
+	if err = s.loadDetails(list); err != nil {
+		return store.TaskPage{}, err
+	}
+
+	result := store.NewTaskPage(list, page.Size(), total, store.SortRank, false)
+	if result.Next != nil {
+		result.Next.Key = []string{strconv.FormatFloat(scores[page.Size()-1], 'g', -1, 64)}
+	}
+	return result, nil
+}
+
+// searchByDate returns tasks matching the where conditions ordered by date.
+func (s *Storage) searchByDate(where string, args params, page store.Page) (store.TaskPage, error) {
+	after, err := page.Cursor(store.SortDate, false)
+	if err != nil {
+		return store.TaskPage{}, err
+	}
+	var total int
+	if err = s.db.QueryRow("SELECT count(*) FROM scheduler WHERE "+where, args...).Scan(&total); err != nil {
+		return store.TaskPage{}, err
+	}
+
+	order, cond := keyset(sortColumns[store.SortDate], false, cursorValues(after), &args)
+	if cond != "" {
+		where += " AND " + cond
+	}
+	rows, err := s.db.Query("SELECT "+taskColumns+" FROM scheduler WHERE "+where+" ORDER BY "+order+
+		" LIMIT "+args.add(page.Size()+1)+" OFFSET "+args.add(page.Offset), args...)
+	if err != nil {
+		return store.TaskPage{}, err
+	}
+	list, err := s.scanDetailed(rows)
+	if err != nil {
+		return store.TaskPage{}, err
+	}
+	return store.NewTaskPage(list, page.Size(), total, store.SortDate, false), nil
+}
+
+const headlineOptions = "StartSel=" + store.MatchStart + ", StopSel=" + store.MatchEnd + ", MaxWords=12, MinWords=4"
+
+// tsQuery writes search terms joined with op in tsquery syntax. Words of a
+// phrase must follow each other.
+func tsQuery(terms []store.SearchTerm, op string) string {
+	quote := func(word string) string {
+		word = strings.ReplaceAll(word, `\`, `\\`)
+		return "'" + strings.ReplaceAll(word, "'", "''") + "'"
+	}
+
+	parts := make([]string, len(terms))
+	for i, term := range terms {
+		words := strings.Fields(term.Text)
+		for j, word := range words {
+			words[j] = quote(word)
+		}
+		parts[i] = strings.Join(words, " <-> ")
+		if term.Prefix {
+			parts[i] += ":*"
+		}
+	}
+	return strings.Join(parts, op)
+}
@@ -0,0 +1,192 @@
+package postgres
+
+import (
+	"database/sql"
+	"github.com/lib/pq"
+	"main/core/database/store"
+	"main/internal/models/tags"
+	"main/internal/models/tasks"
+	"strconv"
+)
+
+// taggedWith is a condition on the task id that holds for tasks the user
+// marked with a tag.
+func taggedWith(user int64, tag string, args *params) string {
+	return `id IN (SELECT tt.task_id FROM task_tags tt JOIN tags t ON t.id = tt.tag_id
+		WHERE t.user_id = ` + args.add(user) + " AND t.name = " + args.add(tag) + ")"
+}
+
+// scanTagged reads tasks selected with taskColumns along with their tags.
+func (s *Storage) scanTagged(rows *sql.Rows) ([]tasks.Task, error) {
+	list, err := scanTasks(rows)
+	if err != nil {
+		return nil, err
+	}
+	if err = s.loadTags(list); err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+// loadTags sets the tags of the tasks in list.
+func (s *Storage) loadTags(list []tasks.Task) error {
+	if len(list) == 0 {
+		return nil
+	}
+	ids := make([]int64, len(list))
+	for i, task := range list {
+		ids[i], _ = strconv.ParseInt(task.ID, 10, 64)
+	}
+	rows, err := s.db.Query(`SELECT tt.task_id, t.name FROM task_tags tt JOIN tags t ON t.id = tt.tag_id
+		WHERE tt.task_id = ANY($1) ORDER BY t.name`, pq.Array(ids))
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	marks := make(map[string][]string)
+	for rows.Next() {
+		var id, name string
+		if err = rows.Scan(&id, &name); err != nil {
+			return err
+		}
+		marks[id] = append(marks[id], name)
+	}
+	if err = rows.Err(); err != nil {
+		return err
+	}
+
+	for i := range list {
+		list[i].Tags = marks[list[i].ID]
+	}
+	return nil
+}
+
+// setTags replaces the tags of a task, creating the ones the user has not
+// used yet.
+func setTags(tx execer, user int64, task int64, names []string) error {
+	if _, err := tx.Exec("DELETE FROM task_tags WHERE task_id = $1", task); err != nil {
+		return err
+	}
+	for _, name := range names {
+		if _, err := tx.Exec("INSERT INTO tags (user_id, name) VALUES ($1, $2) ON CONFLICT DO NOTHING", user, name); err != nil {
+			return err
+		}
+		_, err := tx.Exec("INSERT INTO task_tags (task_id, tag_id) SELECT $1, id FROM tags WHERE user_id = $2 AND name = $3",
+			task, user, name)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *Storage) Tags() ([]tags.Tag, error) {
+	rows, err := s.db.Query(`SELECT t.id, t.name, count(s.id) FROM tags t
+		LEFT JOIN task_tags tt ON tt.tag_id = t.id
+		LEFT JOIN scheduler s ON s.id = tt.task_id AND s.deleted_at = ''
+		WHERE t.user_id = $1 GROUP BY t.id ORDER BY t.name`, s.user)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var result []tags.Tag
+	for rows.Next() {
+		var tag tags.Tag
+		if err = rows.Scan(&tag.ID, &tag.Name, &tag.Tasks); err != nil {
+			return nil, err
+		}
+		result = append(result, tag)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+func (s *Storage) AddTag(tag tags.Tag) (int64, error) {
+	var id int64
+	err := s.db.QueryRow("INSERT INTO tags (user_id, name) VALUES ($1, $2) RETURNING id", s.user, tag.Name).Scan(&id)
+	if isUnique(err) {
+		return 0, store.ErrTagExists
+	}
+	if err != nil {
+		return 0, err
+	}
+	return id, nil
+}
+
+func (s *Storage) UpdateTag(tag tags.Tag) error {
+	key, err := strconv.ParseInt(tag.ID, 10, 64)
+	if err != nil {
+		return store.ErrNoSuchTag
+	}
+	result, err := s.db.Exec("UPDATE tags SET name = $1 WHERE id = $2 AND user_id = $3", tag.Name, key, s.user)
+	if isUnique(err) {
+		return store.ErrTagExists
+	}
+	if err != nil {
+		return err
+	}
+	if n, err := result.RowsAffected(); err == nil && n == 0 {
+		return store.ErrNoSuchTag
+	}
+	return nil
+}
+
+// MergeTags marks the tasks of tag from with tag into and deletes from.
+func (s *Storage) MergeTags(from string, into string) error {
+	fromKey, err := strconv.ParseInt(from, 10, 64)
+	if err != nil {
+		return store.ErrNoSuchTag
+	}
+	intoKey, err := strconv.ParseInt(into, 10, 64)
+	if err != nil {
+		return store.ErrNoSuchTag
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var n int
+	err = tx.QueryRow("SELECT count(*) FROM tags WHERE id IN ($1, $2) AND user_id = $3", fromKey, intoKey, s.user).Scan(&n)
+	if err != nil {
+		return err
+	}
+	if n == 0 || (n == 1 && fromKey != intoKey) {
+		return store.ErrNoSuchTag
+	}
+	if fromKey == intoKey {
+		return nil
+	}
+	_, err = tx.Exec(`INSERT INTO task_tags (task_id, tag_id) SELECT task_id, $1 FROM task_tags WHERE tag_id = $2
+		ON CONFLICT DO NOTHING`, intoKey, fromKey)
+	if err != nil {
+		return err
+	}
+	if _, err = tx.Exec("DELETE FROM tags WHERE id = $1", fromKey); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (s *Storage) DeleteTag(id string) error {
+	key, err := strconv.ParseInt(id, 10, 64)
+	if err != nil {
+		return store.ErrNoSuchTag
+	}
+	result, err := s.db.Exec("DELETE FROM tags WHERE id = $1 AND user_id = $2", key, s.user)
+	if err != nil {
+		return err
+	}
+	if n, err := result.RowsAffected(); err == nil && n == 0 {
+		return store.ErrNoSuchTag
+	}
+	return nil
+}
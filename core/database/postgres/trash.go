@@ -0,0 +1,74 @@
+package postgres
+
+import (
+	"main/core/database/store"
+	"main/internal/models/tasks"
+	"time"
+)
+
+// trashTask marks a task as deleted. Deletion times are stored in UTC so
+// that they compare correctly as strings.
+func trashTask(tx execer, user int64, id int64) error {
+	result, err := tx.Exec("UPDATE scheduler SET deleted_at = $1 WHERE id = $2 AND user_id = $3 AND deleted_at = ''",
+		time.Now().UTC().Format(time.RFC3339), id, user)
+	if err != nil {
+		return err
+	}
+	if n, err := result.RowsAffected(); err == nil && n == 0 {
+		return store.ErrNoSuchTask
+	}
+	return nil
+}
+
+func (s *Storage) Trash() ([]tasks.Task, error) {
+	query := "SELECT " + taskColumns + " FROM scheduler WHERE user_id = $1 AND deleted_at != '' ORDER BY deleted_at DESC, id DESC"
+	rows, err := s.db.Query(query, s.user)
+	if err != nil {
+		return nil, err
+	}
+	return s.scanTagged(rows)
+}
+
+func (s *Storage) RestoreTask(id string) error {
+	key, err := taskID(id)
+	if err != nil {
+		return err
+	}
+	result, err := s.db.Exec("UPDATE scheduler SET deleted_at = '' WHERE id = $1 AND user_id = $2 AND deleted_at != ''", key, s.user)
+	if err != nil {
+		return err
+	}
+	if n, err := result.RowsAffected(); err == nil && n == 0 {
+		return store.ErrNoSuchTask
+	}
+	return nil
+}
+
+func (s *Storage) PurgeTask(id string) error {
+	key, err := taskID(id)
+	if err != nil {
+		return err
+	}
+	result, err := s.db.Exec("DELETE FROM scheduler WHERE id = $1 AND user_id = $2 AND deleted_at != ''", key, s.user)
+	if err != nil {
+		return err
+	}
+	if n, err := result.RowsAffected(); err == nil && n == 0 {
+		return store.ErrNoSuchTask
+	}
+	return nil
+}
+
+func (s *Storage) PurgeTrash(before time.Time) (int64, error) {
+	query := "DELETE FROM scheduler WHERE user_id = $1 AND deleted_at != ''"
+	args := []any{s.user}
+	if !before.IsZero() {
+		query += " AND deleted_at < $2"
+		args = append(args, before.UTC().Format(time.RFC3339))
+	}
+	result, err := s.db.Exec(query, args...)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
@@ -0,0 +1,97 @@
+package postgres
+
+import (
+	"database/sql"
+	"errors"
+	"main/core/database/store"
+	"main/internal/models/users"
+	"time"
+)
+
+const userColumns = "id, login, password_hash, created_at"
+
+func (s *Storage) AddUser(user users.User) (int64, error) {
+	var id int64
+	err := s.db.QueryRow("INSERT INTO users (login, password_hash, created_at) VALUES ($1, $2, $3) RETURNING id",
+		user.Login, user.PasswordHash, time.Now().UTC().Format(time.RFC3339)).Scan(&id)
+	if isUnique(err) {
+		return 0, store.ErrUserExists
+	}
+	if err != nil {
+		return 0, err
+	}
+	return id, nil
+}
+
+func (s *Storage) FindUser(id int64) (*users.User, error) {
+	return s.findUser("SELECT "+userColumns+" FROM users WHERE id = $1", id)
+}
+
+func (s *Storage) FindUserByLogin(login string) (*users.User, error) {
+	return s.findUser("SELECT "+userColumns+" FROM users WHERE login = $1", login)
+}
+
+func (s *Storage) findUser(query string, arg any) (*users.User, error) {
+	var user users.User
+	err := s.db.QueryRow(query, arg).Scan(&user.ID, &user.Login, &user.PasswordHash, &user.CreatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, store.ErrNoSuchUser
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+func (s *Storage) Users() ([]users.User, error) {
+	rows, err := s.db.Query("SELECT " + userColumns + " FROM users ORDER BY id")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var result []users.User
+	for rows.Next() {
+		var user users.User
+		if err = rows.Scan(&user.ID, &user.Login, &user.PasswordHash, &user.CreatedAt); err != nil {
+			return nil, err
+		}
+		result = append(result, user)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// DeleteUser deletes an account with all its tasks, lists and completions.
+func (s *Storage) DeleteUser(id int64) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	result, err := tx.Exec("DELETE FROM users WHERE id = $1", id)
+	if err != nil {
+		return err
+	}
+	if n, err := result.RowsAffected(); err == nil && n == 0 {
+		return store.ErrNoSuchUser
+	}
+	if _, err = tx.Exec("DELETE FROM scheduler WHERE user_id = $1", id); err != nil {
+		return err
+	}
+	if _, err = tx.Exec("DELETE FROM task_completions WHERE user_id = $1", id); err != nil {
+		return err
+	}
+	if _, err = tx.Exec("DELETE FROM lists WHERE user_id = $1", id); err != nil {
+		return err
+	}
+	if _, err = tx.Exec("DELETE FROM tags WHERE user_id = $1", id); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
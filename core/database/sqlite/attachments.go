@@ -0,0 +1,90 @@
+package sqlite
+
+import (
+	"database/sql"
+	"errors"
+	"main/core/database/store"
+	"main/internal/models/attachments"
+	"time"
+)
+
+const attachmentColumns = "id, task_id, name, size, content_type, file, created_at"
+
+// ownAttachment selects attachments of the user's live tasks. It takes the
+// user as its argument.
+const ownAttachment = "task_id IN (SELECT id FROM scheduler WHERE user_id = ? AND deleted_at = '')"
+
+func (s *Storage) Attachments(task string) ([]attachments.Attachment, error) {
+	if err := checkTask(s.db, s.user, task); err != nil {
+		return nil, err
+	}
+	return scanAttachments(s.db.Query("SELECT "+attachmentColumns+" FROM attachments WHERE task_id = ? ORDER BY id", task))
+}
+
+func (s *Storage) AddAttachment(attachment attachments.Attachment) (int64, error) {
+	result, err := s.db.Exec(`INSERT INTO attachments (task_id, name, size, content_type, file, created_at)
+		SELECT id, ?, ?, ?, ?, ? FROM scheduler WHERE id = ? AND user_id = ? AND deleted_at = ''`,
+		attachment.Name, attachment.Size, attachment.ContentType, attachment.File,
+		time.Now().UTC().Format(time.RFC3339), attachment.TaskID, s.user)
+	if err != nil {
+		return 0, err
+	}
+	if n, err := result.RowsAffected(); err == nil && n == 0 {
+		return 0, store.ErrNoSuchTask
+	}
+	return result.LastInsertId()
+}
+
+func (s *Storage) FindAttachment(id string) (*attachments.Attachment, error) {
+	var attachment attachments.Attachment
+	err := s.db.QueryRow("SELECT "+attachmentColumns+" FROM attachments WHERE id = ? AND "+ownAttachment, id, s.user).Scan(
+		&attachment.ID, &attachment.TaskID, &attachment.Name, &attachment.Size,
+		&attachment.ContentType, &attachment.File, &attachment.CreatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, store.ErrNoSuchAttachment
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &attachment, nil
+}
+
+func (s *Storage) DeleteAttachment(id string) error {
+	result, err := s.db.Exec("DELETE FROM attachments WHERE id = ? AND "+ownAttachment, id, s.user)
+	if err != nil {
+		return err
+	}
+	if n, err := result.RowsAffected(); err == nil && n == 0 {
+		return store.ErrNoSuchAttachment
+	}
+	return nil
+}
+
+func (s *Storage) PurgeAttachments() ([]attachments.Attachment, error) {
+	return scanAttachments(s.db.Query("DELETE FROM attachments WHERE task_id NOT IN (SELECT id FROM scheduler) RETURNING " + attachmentColumns))
+}
+
+// scanAttachments reads the attachments returned by a query.
+func scanAttachments(rows *sql.Rows, err error) ([]attachments.Attachment, error) {
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var result []attachments.Attachment
+	for rows.Next() {
+		var attachment attachments.Attachment
+		err = rows.Scan(&attachment.ID, &attachment.TaskID, &attachment.Name, &attachment.Size,
+			&attachment.ContentType, &attachment.File, &attachment.CreatedAt)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, attachment)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
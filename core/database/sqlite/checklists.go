@@ -0,0 +1,115 @@
+package sqlite
+
+import (
+	"database/sql"
+	"errors"
+	"main/core/database/store"
+	"main/internal/models/checklists"
+)
+
+// ownItem selects checklist items of the user's live tasks. It takes the user
+// as its argument.
+const ownItem = "task_id IN (SELECT id FROM scheduler WHERE user_id = ? AND deleted_at = '')"
+
+// checkTask returns store.ErrNoSuchTask unless the user has the task and it is
+// not in the trash.
+func checkTask(q querier, user int64, task string) error {
+	var n int
+	err := q.QueryRow("SELECT count(*) FROM scheduler WHERE id = ? AND user_id = ? AND deleted_at = ''", task, user).Scan(&n)
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return store.ErrNoSuchTask
+	}
+	return nil
+}
+
+func (s *Storage) Checklist(task string) ([]checklists.Item, error) {
+	if err := checkTask(s.db, s.user, task); err != nil {
+		return nil, err
+	}
+	return checklist(s.db, task)
+}
+
+// checklist returns the items of a task in order.
+func checklist(q querier, task string) ([]checklists.Item, error) {
+	rows, err := q.Query("SELECT id, task_id, position, text, done FROM checklist_items WHERE task_id = ? ORDER BY position, id", task)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var result []checklists.Item
+	for rows.Next() {
+		var item checklists.Item
+		if err = rows.Scan(&item.ID, &item.TaskID, &item.Position, &item.Text, &item.Done); err != nil {
+			return nil, err
+		}
+		result = append(result, item)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+func (s *Storage) AddItem(task string, text string) (int64, error) {
+	if err := checkTask(s.db, s.user, task); err != nil {
+		return 0, err
+	}
+	result, err := s.db.Exec(`INSERT INTO checklist_items (task_id, position, text)
+		SELECT ?, coalesce(max(position), 0) + 1, ? FROM checklist_items WHERE task_id = ?`, task, text, task)
+	if err != nil {
+		return 0, err
+	}
+	return result.LastInsertId()
+}
+
+func (s *Storage) ReorderChecklist(task string, ids []string) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err = checkTask(tx, s.user, task); err != nil {
+		return err
+	}
+	items, err := checklist(tx, task)
+	if err != nil {
+		return err
+	}
+	if err = store.CheckOrder(items, ids); err != nil {
+		return err
+	}
+	for i, id := range ids {
+		if _, err = tx.Exec("UPDATE checklist_items SET position = ? WHERE id = ?", i+1, id); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+func (s *Storage) ToggleItem(id string) (bool, error) {
+	var done bool
+	err := s.db.QueryRow("UPDATE checklist_items SET done = NOT done WHERE id = ? AND "+ownItem+" RETURNING done",
+		id, s.user).Scan(&done)
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, store.ErrNoSuchItem
+	}
+	return done, err
+}
+
+func (s *Storage) DeleteItem(id string) error {
+	result, err := s.db.Exec("DELETE FROM checklist_items WHERE id = ? AND "+ownItem, id, s.user)
+	if err != nil {
+		return err
+	}
+	if n, err := result.RowsAffected(); err == nil && n == 0 {
+		return store.ErrNoSuchItem
+	}
+	return nil
+}
@@ -0,0 +1,59 @@
+package sqlite
+
+import (
+	"main/internal/models/completions"
+	"time"
+)
+
+const completionColumns = "id, task_id, title, date, completed_at, note"
+
+// addCompletion records that a task scheduled on date was done. Completion
+// times are stored in UTC so that they compare correctly as strings.
+func addCompletion(tx execer, user int64, task string, title string, date string, note string) error {
+	_, err := tx.Exec("INSERT INTO task_completions (user_id, task_id, title, date, completed_at, note) VALUES (?, ?, ?, ?, ?, ?)",
+		user, task, title, date, time.Now().UTC().Format(time.RFC3339), note)
+	return err
+}
+
+// TaskHistory returns the completions of a task, oldest first. It is kept
+// after the task itself is deleted.
+func (s *Storage) TaskHistory(id string) ([]completions.Completion, error) {
+	query := "SELECT " + completionColumns + " FROM task_completions WHERE task_id = ? AND user_id = ? ORDER BY completed_at, id"
+	return s.queryCompletions(query, id, s.user)
+}
+
+// Completions returns the completions made in [from, to), oldest first. A zero
+// bound leaves that side open.
+func (s *Storage) Completions(from time.Time, to time.Time) ([]completions.Completion, error) {
+	query := "SELECT " + completionColumns + " FROM task_completions WHERE user_id = ? AND completed_at >= ?"
+	args := []any{s.user, from.UTC().Format(time.RFC3339)}
+	if !to.IsZero() {
+		query += " AND completed_at < ?"
+		args = append(args, to.UTC().Format(time.RFC3339))
+	}
+	return s.queryCompletions(query+" ORDER BY completed_at, id", args...)
+}
+
+func (s *Storage) queryCompletions(query string, args ...any) ([]completions.Completion, error) {
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var result []completions.Completion
+	for rows.Next() {
+		var completion completions.Completion
+		if err = rows.Scan(&completion.ID, &completion.TaskID, &completion.Title, &completion.Date,
+			&completion.CompletedAt, &completion.Note); err != nil {
+			return nil, err
+		}
+		result = append(result, completion)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
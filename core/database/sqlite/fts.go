@@ -0,0 +1,94 @@
+package sqlite
+
+import (
+	"database/sql"
+	"errors"
+	"main/core/database/store"
+	"main/internal/models/tasks"
+	"strconv"
+	"strings"
+)
+
+// errNoFTS5 is returned by Open when SQLite is compiled without FTS5, which the
+// full-text index needs.
+var errNoFTS5 = errors.New("SQLite is built without FTS5, build with -tags sqlite_fts5")
+
+func hasFTS5(db *sql.DB) (bool, error) {
+	var used bool
+	err := db.QueryRow("SELECT sqlite_compileoption_used('ENABLE_FTS5')").Scan(&used)
+	return used, err
+}
+
+// ftsQuery writes search terms joined with op in FTS5 query syntax, quoting
+// each of them so that operators typed by the user are taken as text.
+func ftsQuery(terms []store.SearchTerm, op string) string {
+	parts := make([]string, len(terms))
+	for i, term := range terms {
+		parts[i] = `"` + strings.ReplaceAll(term.Text, `"`, `""`) + `"`
+		if term.Prefix {
+			parts[i] += "*"
+		}
+	}
+	return strings.Join(parts, op)
+}
+
+// searchFTS returns tasks matching all terms and the where conditions, best
+// matches first. Title matches weigh more than comment matches.
+func (s *Storage) searchFTS(terms []store.SearchTerm, where string, args []any, page store.Page) (store.TaskPage, error) {
+	after, err := page.Cursor(store.SortRank, false)
+	if err != nil {
+		return store.TaskPage{}, err
+	}
+
+	from := "FROM scheduler_fts JOIN scheduler s ON s.id = scheduler_fts.rowid WHERE scheduler_fts MATCH ? AND " + where
+	args = append([]any{ftsQuery(terms, " ")}, args...)
+	var total int
+	if err = s.db.QueryRow("SELECT count(*) "+from, args...).Scan(&total); err != nil {
+		return store.TaskPage{}, err
+	}
+
+	// bm25 is lower for better matches.
+	query := "SELECT * FROM (SELECT " + searchColumns + `, snippet(scheduler_fts, -1, ?, ?, '…', 12),
+		bm25(scheduler_fts, 10.0, 1.0) AS score ` + from + ")"
+	args = append([]any{store.MatchStart, store.MatchEnd}, args...)
+	if after != nil {
+		score, err := strconv.ParseFloat(after.Key[0], 64)
+		if err != nil {
+			return store.TaskPage{}, store.ErrInvalidCursor
+		}
+		query += " WHERE (score, id) > (?, ?)"
+		args = append(args, score, after.ID)
+	}
+	rows, err := s.db.Query(query+" ORDER BY score, id LIMIT ? OFFSET ?", append(args, page.Size()+1, page.Offset)...)
+	if err != nil {
+		return store.TaskPage{}, err
+	}
+	defer rows.Close()
+
+	var list []tasks.Task
+	var scores []float64
+	for rows.Next() {
+		var task tasks.Task
+		var score float64
+		if err = rows.Scan(&task.ID, &task.Date, &task.Time, &task.Title, &task.Comment, &task.Repeat,
+			&task.Occurrence, &task.Shift, &task.Anchor, &task.DeletedAt, &task.ListID, &task.Priority, &task.Snippet, &score); err != nil {
+			return store.TaskPage{}, err
+		}
+		task.Snippet = store.Highlight(task.Snippet)
+		list = append(list, task)
+		scores = append(scores, score)
+	}
+
+	if err = rows.Err(); err != nil {
+		return store.TaskPage{}, err
+	}
+	if err = s.loadDetails(list); err != nil {
+		return store.TaskPage{}, err
+	}
+
+	result := store.NewTaskPage(list, page.Size(), total, store.SortRank, false)
+	if result.Next != nil {
+		result.Next.Key = []string{strconv.FormatFloat(scores[page.Size()-1], 'g', -1, 64)}
+	}
+	return result, nil
+}
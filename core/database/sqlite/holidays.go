@@ -0,0 +1,69 @@
+package sqlite
+
+import (
+	"main/core/database/store"
+	"main/internal/models/holidays"
+	"main/internal/models/tasks"
+	"main/pkg"
+)
+
+func (s *Storage) Holidays() ([]holidays.Holiday, error) {
+	rows, err := s.db.Query("SELECT date, name FROM holidays ORDER BY date")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var result []holidays.Holiday
+	for rows.Next() {
+		var holiday holidays.Holiday
+		if err = rows.Scan(&holiday.Date, &holiday.Name); err != nil {
+			return nil, err
+		}
+		result = append(result, holiday)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+func (s *Storage) AddHoliday(holiday holidays.Holiday) error {
+	_, err := s.db.Exec("INSERT OR REPLACE INTO holidays (date, name) VALUES (?, ?)", holiday.Date, holiday.Name)
+	return err
+}
+
+func (s *Storage) DeleteHoliday(date string) error {
+	result, err := s.db.Exec("DELETE FROM holidays WHERE date = ?", date)
+	if err != nil {
+		return err
+	}
+	if n, err := result.RowsAffected(); err == nil && n == 0 {
+		return store.ErrNoSuchHoliday
+	}
+	return nil
+}
+
+// Calendar returns the business day calendar built from stored holidays.
+func (s *Storage) Calendar() (*pkg.Calendar, error) {
+	list, err := s.Holidays()
+	if err != nil {
+		return nil, err
+	}
+
+	return store.CalendarOf(list)
+}
+
+// shiftTask is store.ShiftTask with the stored holidays.
+func (s *Storage) shiftTask(task *tasks.Task, base string) error {
+	if task.Shift == "" {
+		return store.ShiftTask(task, base, nil)
+	}
+	cal, err := s.Calendar()
+	if err != nil {
+		return err
+	}
+	return store.ShiftTask(task, base, cal)
+}
@@ -0,0 +1,116 @@
+package sqlite
+
+import (
+	"errors"
+	"github.com/mattn/go-sqlite3"
+	"main/core/database/store"
+	"main/internal/models/lists"
+)
+
+// isUnique reports whether err is a violation of a UNIQUE constraint.
+func isUnique(err error) bool {
+	var sqliteErr sqlite3.Error
+	return errors.As(err, &sqliteErr) && sqliteErr.ExtendedCode == sqlite3.ErrConstraintUnique
+}
+
+func (s *Storage) Lists() ([]lists.List, error) {
+	rows, err := s.db.Query(`SELECT l.id, l.name, count(s.id) FROM lists l
+		LEFT JOIN scheduler s ON s.list_id = l.id AND s.user_id = l.user_id AND s.deleted_at = ''
+		WHERE l.user_id = ? GROUP BY l.id ORDER BY l.name, l.id`, s.user)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var result []lists.List
+	for rows.Next() {
+		var list lists.List
+		if err = rows.Scan(&list.ID, &list.Name, &list.Tasks); err != nil {
+			return nil, err
+		}
+		result = append(result, list)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+func (s *Storage) AddList(list lists.List) (int64, error) {
+	result, err := s.db.Exec("INSERT INTO lists (user_id, name) VALUES (?, ?)", s.user, list.Name)
+	if isUnique(err) {
+		return 0, store.ErrListExists
+	}
+	if err != nil {
+		return 0, err
+	}
+	return result.LastInsertId()
+}
+
+func (s *Storage) UpdateList(list lists.List) error {
+	result, err := s.db.Exec("UPDATE lists SET name = ? WHERE id = ? AND user_id = ?", list.Name, list.ID, s.user)
+	if isUnique(err) {
+		return store.ErrListExists
+	}
+	if err != nil {
+		return err
+	}
+	if n, err := result.RowsAffected(); err == nil && n == 0 {
+		return store.ErrNoSuchList
+	}
+	return nil
+}
+
+// DeleteList deletes a list, leaving its tasks out of any list.
+func (s *Storage) DeleteList(id string) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	result, err := tx.Exec("DELETE FROM lists WHERE id = ? AND user_id = ?", id, s.user)
+	if err != nil {
+		return err
+	}
+	if n, err := result.RowsAffected(); err == nil && n == 0 {
+		return store.ErrNoSuchList
+	}
+	if _, err = tx.Exec("UPDATE scheduler SET list_id = 0 WHERE list_id = ? AND user_id = ?", id, s.user); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// checkList returns store.ErrNoSuchList unless the user has the list or
+// list is zero.
+func (s *Storage) checkList(list int64) error {
+	if list == 0 {
+		return nil
+	}
+	var n int
+	if err := s.db.QueryRow("SELECT count(*) FROM lists WHERE id = ? AND user_id = ?", list, s.user).Scan(&n); err != nil {
+		return err
+	}
+	if n == 0 {
+		return store.ErrNoSuchList
+	}
+	return nil
+}
+
+// MoveTask moves a task to a list, or out of any list if list is zero.
+func (s *Storage) MoveTask(id string, list int64) error {
+	if err := s.checkList(list); err != nil {
+		return err
+	}
+	result, err := s.db.Exec("UPDATE scheduler SET list_id = ? WHERE id = ? AND user_id = ? AND deleted_at = ''", list, id, s.user)
+	if err != nil {
+		return err
+	}
+	if n, err := result.RowsAffected(); err == nil && n == 0 {
+		return store.ErrNoSuchTask
+	}
+	return nil
+}
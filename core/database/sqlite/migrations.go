@@ -0,0 +1,96 @@
+package sqlite
+
+import (
+	"database/sql"
+	"embed"
+	"errors"
+	"fmt"
+	"github.com/mattn/go-sqlite3"
+	"go.uber.org/zap"
+	"io/fs"
+	"main/core/database/migrate"
+	"main/core/logger"
+)
+
+//go:embed migrations/*.sql
+var migrationFiles embed.FS
+
+// Migrations returns the schema migrations of the SQLite database.
+func Migrations() ([]migrate.Migration, error) {
+	fsys, err := fs.Sub(migrationFiles, "migrations")
+	if err != nil {
+		return nil, err
+	}
+	return migrate.Load(fsys)
+}
+
+// Open connects to the database at path without creating or migrating it.
+// It fails if SQLite is built without FTS5.
+func Open(path string) (*sql.DB, error) {
+	db, err := sql.Open(dbDriver, path)
+	if err != nil {
+		return nil, err
+	}
+	if err = db.Ping(); err != nil {
+		db.Close()
+		return nil, err
+	}
+	fts5, err := hasFTS5(db)
+	if err == nil && !fts5 {
+		err = errNoFTS5
+	}
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+	return db, nil
+}
+
+// Migrator returns the migrator of the database.
+func Migrator(db *sql.DB) (migrate.Migrator, error) {
+	migrations, err := Migrations()
+	if err != nil {
+		return migrate.Migrator{}, fmt.Errorf("failed to load migrations: %w", err)
+	}
+	return migrate.Migrator{DB: db, Migrations: migrations}, nil
+}
+
+func migrateDB(db *sql.DB) error {
+	migrator, err := Migrator(db)
+	if err != nil {
+		return err
+	}
+	n, err := migrator.Up()
+	if err != nil {
+		return fmt.Errorf("failed to migrate database: %w", err)
+	}
+	if n > 0 {
+		logger.Get().Info("database migrated", zap.Int("migrations", n))
+	}
+	return nil
+}
+
+// isCorrupt reports whether err is SQLite finding the file damaged or not a
+// database at all.
+func isCorrupt(err error) bool {
+	var sqliteErr sqlite3.Error
+	return errors.As(err, &sqliteErr) && (sqliteErr.Code == sqlite3.ErrCorrupt || sqliteErr.Code == sqlite3.ErrNotADB)
+}
+
+// checkDB makes sure an existing database file is intact and that its schema
+// is not newer than the migrations this build knows about.
+func checkDB(db *sql.DB) error {
+	var result string
+	if err := db.QueryRow("PRAGMA integrity_check").Scan(&result); err != nil {
+		return fmt.Errorf("database is corrupt: %w", err)
+	}
+	if result != "ok" {
+		return fmt.Errorf("database is corrupt: %s", result)
+	}
+
+	migrator, err := Migrator(db)
+	if err != nil {
+		return err
+	}
+	return migrator.Check()
+}
@@ -0,0 +1,9 @@
+CREATE TABLE IF NOT EXISTS scheduler (
+   id INTEGER PRIMARY KEY AUTOINCREMENT,
+   date VARCHAR(8) NOT NULL,
+   title TEXT NOT NULL,
+   comment TEXT DEFAULT "",
+   repeat VARCHAR(128) NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS scheduler_date ON scheduler (date);
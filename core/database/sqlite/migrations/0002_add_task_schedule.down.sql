@@ -0,0 +1,4 @@
+ALTER TABLE scheduler DROP COLUMN anchor;
+ALTER TABLE scheduler DROP COLUMN shift;
+ALTER TABLE scheduler DROP COLUMN occurrence;
+ALTER TABLE scheduler DROP COLUMN time;
@@ -0,0 +1,4 @@
+ALTER TABLE scheduler ADD COLUMN time VARCHAR(4) NOT NULL DEFAULT '';
+ALTER TABLE scheduler ADD COLUMN occurrence INTEGER NOT NULL DEFAULT 1;
+ALTER TABLE scheduler ADD COLUMN shift VARCHAR(4) NOT NULL DEFAULT '';
+ALTER TABLE scheduler ADD COLUMN anchor VARCHAR(8) NOT NULL DEFAULT '';
@@ -0,0 +1,4 @@
+CREATE TABLE holidays (
+   date VARCHAR(8) PRIMARY KEY,
+   name TEXT NOT NULL DEFAULT ''
+);
@@ -0,0 +1,11 @@
+CREATE TABLE task_completions (
+   id INTEGER PRIMARY KEY AUTOINCREMENT,
+   task_id INTEGER NOT NULL,
+   title TEXT NOT NULL,
+   date VARCHAR(8) NOT NULL,
+   completed_at VARCHAR(20) NOT NULL,
+   note TEXT NOT NULL DEFAULT ''
+);
+
+CREATE INDEX task_completions_task ON task_completions (task_id, completed_at);
+CREATE INDEX task_completions_completed ON task_completions (completed_at);
@@ -0,0 +1 @@
+ALTER TABLE scheduler ADD COLUMN deleted_at VARCHAR(20) NOT NULL DEFAULT '';
@@ -0,0 +1,3 @@
+DROP INDEX scheduler_list_id;
+DROP INDEX scheduler_list_title;
+DROP INDEX scheduler_list_date;
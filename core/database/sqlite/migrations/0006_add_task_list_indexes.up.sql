@@ -0,0 +1,3 @@
+CREATE INDEX scheduler_list_date ON scheduler (deleted_at, date, time, id);
+CREATE INDEX scheduler_list_title ON scheduler (deleted_at, title, id);
+CREATE INDEX scheduler_list_id ON scheduler (deleted_at, id);
@@ -0,0 +1,19 @@
+CREATE TABLE users (
+   id INTEGER PRIMARY KEY AUTOINCREMENT,
+   login VARCHAR(64) NOT NULL UNIQUE,
+   password_hash TEXT NOT NULL,
+   created_at VARCHAR(20) NOT NULL
+);
+
+ALTER TABLE scheduler ADD COLUMN user_id INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE task_completions ADD COLUMN user_id INTEGER NOT NULL DEFAULT 0;
+
+DROP INDEX scheduler_list_date;
+DROP INDEX scheduler_list_title;
+DROP INDEX scheduler_list_id;
+CREATE INDEX scheduler_list_date ON scheduler (user_id, deleted_at, date, time, id);
+CREATE INDEX scheduler_list_title ON scheduler (user_id, deleted_at, title, id);
+CREATE INDEX scheduler_list_id ON scheduler (user_id, deleted_at, id);
+
+DROP INDEX task_completions_completed;
+CREATE INDEX task_completions_completed ON task_completions (user_id, completed_at);
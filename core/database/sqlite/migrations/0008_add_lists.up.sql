@@ -0,0 +1,9 @@
+CREATE TABLE lists (
+   id INTEGER PRIMARY KEY AUTOINCREMENT,
+   user_id INTEGER NOT NULL DEFAULT 0,
+   name VARCHAR(64) NOT NULL,
+   UNIQUE (user_id, name)
+);
+
+ALTER TABLE scheduler ADD COLUMN list_id INTEGER NOT NULL DEFAULT 0;
+CREATE INDEX scheduler_in_list ON scheduler (user_id, list_id, deleted_at);
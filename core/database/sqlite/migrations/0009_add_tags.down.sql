@@ -0,0 +1,5 @@
+DROP TRIGGER task_tags_tag_delete;
+DROP TRIGGER task_tags_task_delete;
+
+DROP TABLE task_tags;
+DROP TABLE tags;
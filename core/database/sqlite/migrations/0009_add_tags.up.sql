@@ -0,0 +1,23 @@
+CREATE TABLE tags (
+   id INTEGER PRIMARY KEY AUTOINCREMENT,
+   user_id INTEGER NOT NULL DEFAULT 0,
+   name VARCHAR(32) NOT NULL,
+   UNIQUE (user_id, name)
+);
+
+CREATE TABLE task_tags (
+   task_id INTEGER NOT NULL,
+   tag_id INTEGER NOT NULL,
+   PRIMARY KEY (task_id, tag_id)
+);
+CREATE INDEX task_tags_tag ON task_tags (tag_id);
+
+-- Foreign keys are not enforced, so marks of deleted tasks and tags are
+-- removed by triggers.
+CREATE TRIGGER task_tags_task_delete AFTER DELETE ON scheduler BEGIN
+   DELETE FROM task_tags WHERE task_id = old.id;
+END;
+
+CREATE TRIGGER task_tags_tag_delete AFTER DELETE ON tags BEGIN
+   DELETE FROM task_tags WHERE tag_id = old.id;
+END;
@@ -0,0 +1,2 @@
+DROP TRIGGER checklist_items_task_delete;
+DROP TABLE checklist_items;
@@ -0,0 +1,14 @@
+CREATE TABLE checklist_items (
+   id INTEGER PRIMARY KEY AUTOINCREMENT,
+   task_id INTEGER NOT NULL,
+   position INTEGER NOT NULL,
+   text VARCHAR(256) NOT NULL,
+   done BOOLEAN NOT NULL DEFAULT FALSE
+);
+CREATE INDEX checklist_items_task ON checklist_items (task_id, position);
+
+-- Foreign keys are not enforced, so items of deleted tasks are removed by a
+-- trigger.
+CREATE TRIGGER checklist_items_task_delete AFTER DELETE ON scheduler BEGIN
+   DELETE FROM checklist_items WHERE task_id = old.id;
+END;
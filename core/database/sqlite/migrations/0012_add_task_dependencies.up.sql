@@ -0,0 +1,12 @@
+CREATE TABLE task_dependencies (
+   task_id INTEGER NOT NULL,
+   blocker_id INTEGER NOT NULL,
+   PRIMARY KEY (task_id, blocker_id)
+);
+CREATE INDEX task_dependencies_blocker ON task_dependencies (blocker_id);
+
+-- Foreign keys are not enforced, so dependencies of deleted tasks are removed
+-- by a trigger.
+CREATE TRIGGER task_dependencies_task_delete AFTER DELETE ON scheduler BEGIN
+   DELETE FROM task_dependencies WHERE task_id = old.id OR blocker_id = old.id;
+END;
@@ -0,0 +1,12 @@
+-- Attachments outlive their tasks until the store is asked to purge them, so
+-- that their files can be removed from disk first.
+CREATE TABLE attachments (
+   id INTEGER PRIMARY KEY AUTOINCREMENT,
+   task_id INTEGER NOT NULL,
+   name VARCHAR(255) NOT NULL,
+   size INTEGER NOT NULL,
+   content_type VARCHAR(255) NOT NULL,
+   file VARCHAR(64) NOT NULL,
+   created_at VARCHAR(32) NOT NULL
+);
+CREATE INDEX attachments_task ON attachments (task_id);
@@ -0,0 +1,4 @@
+DROP TRIGGER scheduler_fts_update;
+DROP TRIGGER scheduler_fts_delete;
+DROP TRIGGER scheduler_fts_insert;
+DROP TABLE scheduler_fts;
@@ -0,0 +1,22 @@
+-- Full-text index of task titles and comments. FTS5 is compiled into SQLite
+-- with the sqlite_fts5 build tag, which Open insists on. Databases that had
+-- the index set up at startup by earlier builds keep it and get it rebuilt.
+CREATE VIRTUAL TABLE IF NOT EXISTS scheduler_fts USING fts5 (
+   title, comment, content = 'scheduler', content_rowid = 'id',
+   tokenize = 'unicode61 remove_diacritics 2'
+);
+
+CREATE TRIGGER IF NOT EXISTS scheduler_fts_insert AFTER INSERT ON scheduler BEGIN
+   INSERT INTO scheduler_fts (rowid, title, comment) VALUES (new.id, new.title, new.comment);
+END;
+
+CREATE TRIGGER IF NOT EXISTS scheduler_fts_delete AFTER DELETE ON scheduler BEGIN
+   INSERT INTO scheduler_fts (scheduler_fts, rowid, title, comment) VALUES ('delete', old.id, old.title, old.comment);
+END;
+
+CREATE TRIGGER IF NOT EXISTS scheduler_fts_update AFTER UPDATE OF title, comment ON scheduler BEGIN
+   INSERT INTO scheduler_fts (scheduler_fts, rowid, title, comment) VALUES ('delete', old.id, old.title, old.comment);
+   INSERT INTO scheduler_fts (rowid, title, comment) VALUES (new.id, new.title, new.comment);
+END;
+
+INSERT INTO scheduler_fts (scheduler_fts) VALUES ('rebuild');
@@ -0,0 +1,8 @@
+DROP TABLE scheduler_fts;
+
+CREATE VIRTUAL TABLE scheduler_fts USING fts5 (
+   title, comment, content = 'scheduler', content_rowid = 'id',
+   tokenize = 'unicode61 remove_diacritics 2'
+);
+
+INSERT INTO scheduler_fts (scheduler_fts) VALUES ('rebuild');
@@ -0,0 +1,10 @@
+-- Index word stems, so that "report" finds "reports" too. The porter stemmer
+-- knows English only; other words are indexed as they are written.
+DROP TABLE scheduler_fts;
+
+CREATE VIRTUAL TABLE scheduler_fts USING fts5 (
+   title, comment, content = 'scheduler', content_rowid = 'id',
+   tokenize = 'porter unicode61 remove_diacritics 2'
+);
+
+INSERT INTO scheduler_fts (scheduler_fts) VALUES ('rebuild');
@@ -0,0 +1,79 @@
+package sqlite
+
+import "main/core/database/store"
+
+// searchColumns is taskColumns for search queries, which name scheduler s.
+const searchColumns = "s.id, s.date, s.time, s.title, s.comment, s.repeat, s.occurrence, s.shift, s.anchor, s.deleted_at, s.list_id, s.priority"
+
+// searchDateColumns are the columns search results not ranked by the
+// full-text index are ordered by.
+var searchDateColumns = []string{"s.date", "s.priority", "s.time", "s.id"}
+
+// SearchTasks returns tasks matching the filter. Text is matched with the
+// full-text index and tasks are ranked by relevance; filters without text
+// give tasks ordered by date.
+func (s *Storage) SearchTasks(filter store.Filter, page store.Page) (store.TaskPage, error) {
+	if filter.Empty() {
+		return store.TaskPage{}, nil
+	}
+
+	where, args := "s.user_id = ? AND s.deleted_at = ''", []any{s.user}
+	add := func(condition string, values ...any) {
+		where += " AND " + condition
+		args = append(args, values...)
+	}
+	if filter.After != "" {
+		add("s.date >= ?", filter.After)
+	}
+	if filter.Before != "" {
+		add("s.date < ?", filter.Before)
+	}
+	if filter.Repeat != nil {
+		if *filter.Repeat {
+			add("s.repeat <> ''")
+		} else {
+			add("s.repeat = ''")
+		}
+	}
+
+	for _, tag := range filter.Tags {
+		add(taggedWith("s.id"), s.user, tag)
+	}
+	if filter.Priority != 0 {
+		add("s.priority = ?", filter.Priority)
+	}
+
+	if excludes := filter.Excludes(); len(excludes) > 0 {
+		add("s.id NOT IN (SELECT rowid FROM scheduler_fts WHERE scheduler_fts MATCH ?)", ftsQuery(excludes, " OR "))
+	}
+	if matches := filter.Matches(); len(matches) > 0 {
+		return s.searchFTS(matches, where, args, page)
+	}
+
+	after, err := page.Cursor(store.SortDate, false)
+	if err != nil {
+		return store.TaskPage{}, err
+	}
+	var total int
+	if err = s.db.QueryRow("SELECT count(*) FROM scheduler s WHERE "+where, args...).Scan(&total); err != nil {
+		return store.TaskPage{}, err
+	}
+
+	order, cond := keyset(searchDateColumns, false, cursorValues(after))
+	if cond != "" {
+		add(cond, cursorValues(after)...)
+	}
+	query := "SELECT " + searchColumns + " FROM scheduler s WHERE " + where + " ORDER BY " + order + " LIMIT ? OFFSET ?"
+	rows, err := s.db.Query(query, append(args, page.Size()+1, page.Offset)...)
+	if err != nil {
+		return store.TaskPage{}, err
+	}
+	list, err := scanTasks(rows)
+	if err != nil {
+		return store.TaskPage{}, err
+	}
+	if err = s.loadDetails(list); err != nil {
+		return store.TaskPage{}, err
+	}
+	return store.NewTaskPage(list, page.Size(), total, store.SortDate, false), nil
+}
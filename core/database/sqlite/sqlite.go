@@ -6,55 +6,73 @@ import (
 	"fmt"
 	_ "github.com/mattn/go-sqlite3"
 	"go.uber.org/zap"
-	"main/core/config"
+	"main/core/database/store"
 	"main/core/logger"
 	"main/internal/models/tasks"
 	"main/pkg"
 	"os"
-	"time"
+	"strings"
 )
 
 const dbDriver = "sqlite3"
-const limit = 10
-
-var ErrNoSuchTask = errors.New("no such task")
+const taskColumns = "id, date, time, title, comment, repeat, occurrence, shift, anchor, deleted_at, list_id, priority"
 
+// Storage keeps tasks in an SQLite database file.
 type Storage struct {
 	db *sql.DB
+	// user owns the tasks the storage works with.
+	user int64
 }
 
-var db Storage
+var _ store.Store = (*Storage)(nil)
 
-func Init() {
-	if err := db.initDB(); err != nil {
-		logger.Get().Fatal("failed to init database", zap.Error(err))
+// New opens the database at path, creating it if there is none, and brings
+// its schema up to date.
+func New(path string) (*Storage, error) {
+	s := &Storage{}
+	if err := s.initDB(path); err != nil {
+		return nil, err
 	}
-	return
+	return s, nil
 }
 
-func Get() *Storage {
-	if db.db == nil {
-		Init()
-	}
-	return &db
+func (s *Storage) Close() error {
+	return s.db.Close()
+}
+
+func (s *Storage) ForUser(id int64) store.Store {
+	view := *s
+	view.user = id
+	return &view
 }
 
-func (s *Storage) initDB() error {
-	if err := createDB(config.Get().DB.Path); err != nil {
+func (s *Storage) initDB(path string) error {
+	exists, err := dbExists(path)
+	if err != nil {
 		return err
 	}
+	if !exists {
+		if err = createDB(path); err != nil {
+			return err
+		}
+	}
 
-	db, err := sql.Open(dbDriver, config.Get().DB.Path)
+	db, err := Open(path)
+	if isCorrupt(err) {
+		return fmt.Errorf("cannot use database %s: database is corrupt: %w", path, err)
+	}
 	if err != nil {
-		logger.Get().Error("failed to open database")
-		return err
+		return fmt.Errorf("cannot open database %s: %w", path, err)
 	}
 
-	if err = db.Ping(); err != nil {
-		return err
+	if exists {
+		if err = checkDB(db); err != nil {
+			db.Close()
+			return fmt.Errorf("cannot use database %s: %w", path, err)
+		}
 	}
 
-	if err = createNewTable(db); err != nil {
+	if err = migrateDB(db); err != nil {
 		return err
 	}
 
@@ -63,8 +81,26 @@ func (s *Storage) initDB() error {
 	return nil
 }
 
+func dbExists(dbPath string) (bool, error) {
+	info, err := os.Stat(dbPath)
+	if errors.Is(err, os.ErrNotExist) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to check db file: %w", err)
+	}
+	if info.IsDir() {
+		return false, fmt.Errorf("db path %s is a directory", dbPath)
+	}
+	return true, nil
+}
+
 func createDB(dbPath string) error {
-	if _, err := os.Create(dbPath); err != nil {
+	f, err := os.Create(dbPath)
+	if err != nil {
+		return fmt.Errorf("failed to create db file: %w", err)
+	}
+	if err = f.Close(); err != nil {
 		return fmt.Errorf("failed to create db file: %w", err)
 	}
 
@@ -73,26 +109,67 @@ func createDB(dbPath string) error {
 	return nil
 }
 
-func createNewTable(db *sql.DB) error {
-	if _, err := db.Exec(`
-		CREATE TABLE IF NOT EXISTS scheduler (
-		   id INTEGER PRIMARY KEY AUTOINCREMENT,
-		   date VARCHAR(8) NOT NULL,
-		   title TEXT NOT NULL,
-		   comment TEXT DEFAULT "",
-		   repeat VARCHAR(128) NOT NULL
-   		);
-	
-   		CREATE INDEX scheduler_date ON scheduler (date);
-   `); err != nil {
-		return fmt.Errorf("failed to create new table: %w", err)
+type scanner interface {
+	Scan(dest ...any) error
+}
+
+type execer interface {
+	Exec(query string, args ...any) (sql.Result, error)
+}
+
+// querier is a database or a transaction.
+type querier interface {
+	Query(query string, args ...any) (*sql.Rows, error)
+	QueryRow(query string, args ...any) *sql.Row
+}
+
+// scanTask reads a row selected with taskColumns.
+func scanTask(row scanner, task *tasks.Task) error {
+	return row.Scan(&task.ID, &task.Date, &task.Time, &task.Title, &task.Comment, &task.Repeat,
+		&task.Occurrence, &task.Shift, &task.Anchor, &task.DeletedAt, &task.ListID, &task.Priority)
+}
+
+func scanTasks(rows *sql.Rows) ([]tasks.Task, error) {
+	defer rows.Close()
+
+	var result []tasks.Task
+	for rows.Next() {
+		var task tasks.Task
+		if err := scanTask(rows, &task); err != nil {
+			return nil, err
+		}
+		result = append(result, task)
 	}
 
-	return nil
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return result, nil
 }
 
 func (s *Storage) AddTaskDB(task tasks.Task) (int64, error) {
-	result, err := s.db.Exec("INSERT INTO scheduler (date, title, comment, repeat) VALUES (?, ?, ?, ?)", task.Date, task.Title, task.Comment, task.Repeat)
+	if err := s.shiftTask(&task, task.Date); err != nil {
+		return 0, err
+	}
+	if err := s.checkList(task.ListID); err != nil {
+		return 0, err
+	}
+	if task.Priority == 0 {
+		task.Priority = tasks.PriorityLowest
+	}
+	if task.Occurrence == 0 {
+		task.Occurrence = 1
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	result, err := tx.Exec("INSERT INTO scheduler (user_id, list_id, priority, date, time, title, comment, repeat, occurrence, shift, anchor) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)",
+		s.user, task.ListID, task.Priority, task.Date, task.Time, task.Title, task.Comment, task.Repeat, task.Occurrence, task.Shift, task.Anchor)
 	if err != nil {
 		return 0, err
 	}
@@ -100,7 +177,10 @@ func (s *Storage) AddTaskDB(task tasks.Task) (int64, error) {
 	if err != nil {
 		return 0, err
 	}
-	return id, nil
+	if err = setTags(tx, s.user, id, task.Tags); err != nil {
+		return 0, err
+	}
+	return id, tx.Commit()
 }
 
 func (s *Storage) FindTask(id string) (*tasks.Task, error) {
@@ -109,60 +189,165 @@ func (s *Storage) FindTask(id string) (*tasks.Task, error) {
 		return nil, errors.New("empty task id")
 	}
 
-	query := "SELECT id, date, title, comment, repeat FROM scheduler WHERE id = ?"
-	err := s.db.QueryRow(query, id).Scan(&task.ID, &task.Date, &task.Title, &task.Comment, &task.Repeat)
+	query := "SELECT " + taskColumns + " FROM scheduler WHERE id = ? AND user_id = ? AND deleted_at = ''"
+	err := scanTask(s.db.QueryRow(query, id, s.user), &task)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
-			return nil, ErrNoSuchTask
+			return nil, store.ErrNoSuchTask
 		}
 		logger.Get().Error("failed to query task", zap.Error(err))
 		return nil, err
 	}
 
-	return &task, nil
+	list := []tasks.Task{task}
+	if err = s.loadDetails(list); err != nil {
+		return nil, err
+	}
+	return &list[0], nil
 }
 
 func (s *Storage) UpdateTask(task tasks.Task) error {
-	query := `UPDATE scheduler SET date = ?, title = ?, comment = ?, repeat = ? WHERE id = ?`
-	_, err := s.db.Exec(query, task.Date, task.Title, task.Comment, task.Repeat, task.ID)
+	// A shifted task keeps its original date unless the date itself is changed.
+	base := task.Date
+	if current, err := s.FindTask(task.ID); err == nil && current.Date == task.Date && current.Anchor != "" {
+		base = current.Anchor
+	}
+	if err := s.shiftTask(&task, base); err != nil {
+		return err
+	}
+
+	// A changed repeat rule starts a new series, so its occurrence count is
+	// reset. The priority is kept unless given.
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	query := `UPDATE scheduler SET date = ?, time = ?, title = ?, comment = ?,
+		occurrence = CASE WHEN repeat = ? THEN occurrence ELSE 1 END, repeat = ?, shift = ?, anchor = ?,
+		priority = CASE WHEN ? = 0 THEN priority ELSE ? END
+		WHERE id = ? AND user_id = ? AND deleted_at = ''`
+	result, err := tx.Exec(query, task.Date, task.Time, task.Title, task.Comment, task.Repeat, task.Repeat,
+		task.Shift, task.Anchor, task.Priority, task.Priority, task.ID, s.user)
 	if err != nil {
-		if errors.Is(err, sql.ErrNoRows) {
-			return ErrNoSuchTask
-		}
 		logger.Get().Error("failed to update task", zap.Error(err))
 		return err
 	}
+	// Tags are kept unless given.
+	if task.Tags != nil {
+		if n, err := result.RowsAffected(); err == nil && n == 0 {
+			return store.ErrNoSuchTask
+		}
+		if err = setTags(tx, s.user, task.ID, task.Tags); err != nil {
+			return err
+		}
+	}
 
-	return nil
+	return tx.Commit()
+}
+
+// sortColumns are the columns each sort field orders by.
+var sortColumns = map[string][]string{
+	store.SortDate:  {"date", "priority", "time", "id"},
+	store.SortTitle: {"title", "id"},
+	store.SortID:    {"id"},
+}
+
+// keyset returns the ORDER BY clause for columns, and the condition that
+// selects rows after the given values of them if there are any.
+func keyset(columns []string, desc bool, after []any) (string, string) {
+	order, op := strings.Join(columns, ", "), ">"
+	if desc {
+		order, op = strings.Join(columns, " DESC, ")+" DESC", "<"
+	}
+	if after == nil {
+		return order, ""
+	}
+	marks := strings.TrimSuffix(strings.Repeat("?, ", len(after)), ", ")
+	return order, "(" + strings.Join(columns, ", ") + ") " + op + " (" + marks + ")"
+}
+
+// cursorValues returns the values of the sort columns at the cursor.
+func cursorValues(cursor *store.Cursor) []any {
+	if cursor == nil {
+		return nil
+	}
+	values := make([]any, 0, len(cursor.Key)+1)
+	for _, value := range cursor.Key {
+		values = append(values, value)
+	}
+	return append(values, cursor.ID)
 }
 
-func (s *Storage) Tasks(offset int) ([]tasks.Task, error) {
-	query := fmt.Sprintf("SELECT * FROM scheduler ORDER BY date LIMIT %d OFFSET %d", limit, offset)
-	rows, err := s.db.Query(query)
+func (s *Storage) Tasks(query store.TaskQuery) (store.TaskPage, error) {
+	field := query.SortField()
+	columns, ok := sortColumns[field]
+	if !ok {
+		return store.TaskPage{}, fmt.Errorf("unknown sort field %q", field)
+	}
+	after, err := query.Cursor(field, query.Desc)
 	if err != nil {
-		return nil, err
+		return store.TaskPage{}, err
 	}
-	defer rows.Close()
 
-	var result []tasks.Task
-	for rows.Next() {
-		var task tasks.Task
-		if err = rows.Scan(&task.ID, &task.Date, &task.Title, &task.Comment, &task.Repeat); err != nil {
-			return nil, err
+	where, args := "user_id = ? AND deleted_at = ''", []any{s.user}
+	if query.From != "" {
+		where += " AND date >= ?"
+		args = append(args, query.From)
+	}
+	if query.To != "" {
+		where += " AND date <= ?"
+		args = append(args, query.To)
+	}
+	if query.List != 0 {
+		where += " AND list_id = ?"
+		args = append(args, query.List)
+	}
+	if query.Tag != "" {
+		where += " AND " + taggedWith("id")
+		args = append(args, s.user, query.Tag)
+	}
+	if query.Priority != 0 {
+		where += " AND priority = ?"
+		args = append(args, query.Priority)
+	}
+	if query.Recurring != nil {
+		if *query.Recurring {
+			where += " AND repeat <> ''"
+		} else {
+			where += " AND repeat = ''"
 		}
-		result = append(result, task)
 	}
 
-	if err = rows.Err(); err != nil {
-		return nil, err
+	var total int
+	if err = s.db.QueryRow("SELECT count(*) FROM scheduler WHERE "+where, args...).Scan(&total); err != nil {
+		return store.TaskPage{}, err
 	}
 
-	return result, nil
+	order, cond := keyset(columns, query.Desc, cursorValues(after))
+	if cond != "" {
+		where += " AND " + cond
+		args = append(args, cursorValues(after)...)
+	}
+	rows, err := s.db.Query("SELECT "+taskColumns+" FROM scheduler WHERE "+where+" ORDER BY "+order+" LIMIT ? OFFSET ?",
+		append(args, query.Size()+1, query.Offset)...)
+	if err != nil {
+		return store.TaskPage{}, err
+	}
+	list, err := scanTasks(rows)
+	if err != nil {
+		return store.TaskPage{}, err
+	}
+	if err = s.loadDetails(list); err != nil {
+		return store.TaskPage{}, err
+	}
+	return store.NewTaskPage(list, query.Size(), total, field, query.Desc), nil
 }
 
-func (s *Storage) SearchTasks(search string, offset int) ([]tasks.Task, error) {
-	query := "SELECT * FROM scheduler WHERE title LIKE ? OR comment LIKE ? LIMIT ? OFFSET ?"
-	rows, err := s.db.Query(query, "%"+search+"%", "%"+search+"%", limit, offset)
+func (s *Storage) TasksByDate(date string) ([]tasks.Task, error) {
+	query := "SELECT " + taskColumns + " FROM scheduler WHERE date = ? AND user_id = ? AND deleted_at = '' ORDER BY priority, time"
+	rows, err := s.db.Query(query, date, s.user)
 	if err != nil {
 		return nil, err
 	}
@@ -171,17 +356,27 @@ func (s *Storage) SearchTasks(search string, offset int) ([]tasks.Task, error) {
 	var result []tasks.Task
 	for rows.Next() {
 		var task tasks.Task
-		if err = rows.Scan(&task.ID, &task.Date, &task.Title, &task.Comment, &task.Repeat); err != nil {
+		if err = scanTask(rows, &task); err != nil {
 			return nil, err
 		}
 		result = append(result, task)
 	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if err = s.loadDetails(result); err != nil {
+		return nil, err
+	}
 	return result, nil
 }
 
-func (s *Storage) TasksByDate(date string) ([]tasks.Task, error) {
-	query := "SELECT * FROM scheduler WHERE date = ?"
-	rows, err := s.db.Query(query, date)
+// TasksUntil returns every task scheduled on or before the given date, which
+// is every task that can have an occurrence up to that date.
+func (s *Storage) TasksUntil(date string) ([]tasks.Task, error) {
+	query := "SELECT " + taskColumns + " FROM scheduler WHERE date <= ? AND user_id = ? AND deleted_at = '' ORDER BY date, priority, time, id"
+	rows, err := s.db.Query(query, date, s.user)
 	if err != nil {
 		return nil, err
 	}
@@ -190,50 +385,78 @@ func (s *Storage) TasksByDate(date string) ([]tasks.Task, error) {
 	var result []tasks.Task
 	for rows.Next() {
 		var task tasks.Task
-		if err = rows.Scan(&task.ID, &task.Date, &task.Title, &task.Comment, &task.Repeat); err != nil {
+		if err = scanTask(rows, &task); err != nil {
 			return nil, err
 		}
 		result = append(result, task)
 	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if err = s.loadDetails(result); err != nil {
+		return nil, err
+	}
 	return result, nil
 }
 
-func (s *Storage) DoneTask(id string) error {
+// DoneTask rolls a repeating task forward to its next occurrence or moves a
+// one-off task to the trash, recording the completion with an optional note.
+func (s *Storage) DoneTask(id string, note string) error {
 	var task tasks.Task
-	if err := s.db.QueryRow("SELECT id, date, title, comment, repeat FROM scheduler WHERE id = ?", id).Scan(
-		&task.ID, &task.Date, &task.Title, &task.Comment, &task.Repeat); err != nil {
-		return ErrNoSuchTask
+	query := "SELECT " + taskColumns + " FROM scheduler WHERE id = ? AND user_id = ? AND deleted_at = ''"
+	if err := scanTask(s.db.QueryRow(query, id, s.user), &task); err != nil {
+		return store.ErrNoSuchTask
+	}
+	list := []tasks.Task{task}
+	if err := s.loadBlocked(list); err != nil {
+		return err
+	}
+	if list[0].Blocked {
+		return store.ErrTaskBlocked
 	}
 
-	if task.Repeat == "" {
-		_, err := s.db.Exec("DELETE FROM scheduler WHERE id = ?", id)
-		if err != nil {
-			return errors.New("задача не найдена")
-		}
-	} else {
-		date, err := pkg.NextDate(time.Now(), task.Date, task.Repeat)
+	next, anchor, occurrence := "", "", 0
+	if task.Repeat != "" {
+		cal, err := s.Calendar()
 		if err != nil {
 			return err
 		}
-		task.Date = date
-		if err = s.UpdateTask(task); err != nil {
+		next, anchor, occurrence, err = store.NextOccurrence(task, cal)
+		if err != nil && !errors.Is(err, pkg.ErrRepeatFinished) {
 			return err
 		}
 	}
 
-	return nil
-}
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
 
-func (s *Storage) DeleteTask(id string) error {
-	var exists bool
-	err := s.db.QueryRow("SELECT exists(SELECT 1 FROM scheduler WHERE id=?)", id).Scan(&exists)
-	if err != nil || !exists {
-		return ErrNoSuchTask
+	if err = addCompletion(tx, s.user, task.ID, task.Title, task.Date, note); err != nil {
+		return err
 	}
 
-	if _, err = s.db.Exec("DELETE FROM scheduler WHERE id = ?", id); err != nil {
+	if next != "" {
+		_, err = tx.Exec("UPDATE scheduler SET date = ?, anchor = ?, occurrence = ? WHERE id = ?",
+			next, anchor, occurrence, task.ID)
+		if err == nil {
+			// The next occurrence starts with its checklist unchecked.
+			_, err = tx.Exec("UPDATE checklist_items SET done = FALSE WHERE task_id = ?", task.ID)
+		}
+	} else {
+		err = trashTask(tx, s.user, id)
+	}
+	if err != nil {
 		return err
 	}
 
-	return nil
+	return tx.Commit()
+}
+
+// DeleteTask moves a task to the trash.
+func (s *Storage) DeleteTask(id string) error {
+	return trashTask(s.db, s.user, id)
 }
@@ -0,0 +1,158 @@
+package sqlite
+
+import (
+	"main/core/database/store"
+	"main/internal/models/tags"
+	"main/internal/models/tasks"
+	"strings"
+)
+
+// taggedWith is a condition on the task id in column that holds for tasks the
+// user marked with a tag. It takes the user and the tag name.
+func taggedWith(column string) string {
+	return column + ` IN (SELECT tt.task_id FROM task_tags tt JOIN tags t ON t.id = tt.tag_id
+		WHERE t.user_id = ? AND t.name = ?)`
+}
+
+// loadTags sets the tags of the tasks in list.
+func (s *Storage) loadTags(list []tasks.Task) error {
+	if len(list) == 0 {
+		return nil
+	}
+	ids := make([]any, len(list))
+	for i, task := range list {
+		ids[i] = task.ID
+	}
+	rows, err := s.db.Query(`SELECT tt.task_id, t.name FROM task_tags tt JOIN tags t ON t.id = tt.tag_id
+		WHERE tt.task_id IN (?`+strings.Repeat(", ?", len(ids)-1)+") ORDER BY t.name", ids...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	marks := make(map[string][]string)
+	for rows.Next() {
+		var id, name string
+		if err = rows.Scan(&id, &name); err != nil {
+			return err
+		}
+		marks[id] = append(marks[id], name)
+	}
+	if err = rows.Err(); err != nil {
+		return err
+	}
+
+	for i := range list {
+		list[i].Tags = marks[list[i].ID]
+	}
+	return nil
+}
+
+// setTags replaces the tags of a task, creating the ones the user has not
+// used yet.
+func setTags(tx execer, user int64, task any, names []string) error {
+	if _, err := tx.Exec("DELETE FROM task_tags WHERE task_id = ?", task); err != nil {
+		return err
+	}
+	for _, name := range names {
+		if _, err := tx.Exec("INSERT OR IGNORE INTO tags (user_id, name) VALUES (?, ?)", user, name); err != nil {
+			return err
+		}
+		_, err := tx.Exec("INSERT INTO task_tags (task_id, tag_id) SELECT ?, id FROM tags WHERE user_id = ? AND name = ?",
+			task, user, name)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *Storage) Tags() ([]tags.Tag, error) {
+	rows, err := s.db.Query(`SELECT t.id, t.name, count(s.id) FROM tags t
+		LEFT JOIN task_tags tt ON tt.tag_id = t.id
+		LEFT JOIN scheduler s ON s.id = tt.task_id AND s.deleted_at = ''
+		WHERE t.user_id = ? GROUP BY t.id ORDER BY t.name`, s.user)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var result []tags.Tag
+	for rows.Next() {
+		var tag tags.Tag
+		if err = rows.Scan(&tag.ID, &tag.Name, &tag.Tasks); err != nil {
+			return nil, err
+		}
+		result = append(result, tag)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+func (s *Storage) AddTag(tag tags.Tag) (int64, error) {
+	result, err := s.db.Exec("INSERT INTO tags (user_id, name) VALUES (?, ?)", s.user, tag.Name)
+	if isUnique(err) {
+		return 0, store.ErrTagExists
+	}
+	if err != nil {
+		return 0, err
+	}
+	return result.LastInsertId()
+}
+
+func (s *Storage) UpdateTag(tag tags.Tag) error {
+	result, err := s.db.Exec("UPDATE tags SET name = ? WHERE id = ? AND user_id = ?", tag.Name, tag.ID, s.user)
+	if isUnique(err) {
+		return store.ErrTagExists
+	}
+	if err != nil {
+		return err
+	}
+	if n, err := result.RowsAffected(); err == nil && n == 0 {
+		return store.ErrNoSuchTag
+	}
+	return nil
+}
+
+// MergeTags marks the tasks of tag from with tag into and deletes from.
+func (s *Storage) MergeTags(from string, into string) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var n int
+	if err = tx.QueryRow("SELECT count(*) FROM tags WHERE id IN (?, ?) AND user_id = ?", from, into, s.user).Scan(&n); err != nil {
+		return err
+	}
+	if n == 0 || (n == 1 && from != into) {
+		return store.ErrNoSuchTag
+	}
+	if from == into {
+		return nil
+	}
+	_, err = tx.Exec("INSERT OR IGNORE INTO task_tags (task_id, tag_id) SELECT task_id, ? FROM task_tags WHERE tag_id = ?", into, from)
+	if err != nil {
+		return err
+	}
+	if _, err = tx.Exec("DELETE FROM tags WHERE id = ?", from); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (s *Storage) DeleteTag(id string) error {
+	result, err := s.db.Exec("DELETE FROM tags WHERE id = ? AND user_id = ?", id, s.user)
+	if err != nil {
+		return err
+	}
+	if n, err := result.RowsAffected(); err == nil && n == 0 {
+		return store.ErrNoSuchTag
+	}
+	return nil
+}
@@ -0,0 +1,88 @@
+package sqlite
+
+import (
+	"main/core/database/store"
+	"main/internal/models/tasks"
+	"time"
+)
+
+// trashTask marks a task as deleted. Deletion times are stored in UTC so
+// that they compare correctly as strings.
+func trashTask(tx execer, user int64, id string) error {
+	result, err := tx.Exec("UPDATE scheduler SET deleted_at = ? WHERE id = ? AND user_id = ? AND deleted_at = ''",
+		time.Now().UTC().Format(time.RFC3339), id, user)
+	if err != nil {
+		return err
+	}
+	if n, err := result.RowsAffected(); err == nil && n == 0 {
+		return store.ErrNoSuchTask
+	}
+	return nil
+}
+
+// Trash returns deleted tasks, most recently deleted first.
+func (s *Storage) Trash() ([]tasks.Task, error) {
+	query := "SELECT " + taskColumns + " FROM scheduler WHERE user_id = ? AND deleted_at != '' ORDER BY deleted_at DESC, id DESC"
+	rows, err := s.db.Query(query, s.user)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var result []tasks.Task
+	for rows.Next() {
+		var task tasks.Task
+		if err = scanTask(rows, &task); err != nil {
+			return nil, err
+		}
+		result = append(result, task)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if err = s.loadTags(result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func (s *Storage) RestoreTask(id string) error {
+	result, err := s.db.Exec("UPDATE scheduler SET deleted_at = '' WHERE id = ? AND user_id = ? AND deleted_at != ''", id, s.user)
+	if err != nil {
+		return err
+	}
+	if n, err := result.RowsAffected(); err == nil && n == 0 {
+		return store.ErrNoSuchTask
+	}
+	return nil
+}
+
+// PurgeTask permanently deletes a task from the trash.
+func (s *Storage) PurgeTask(id string) error {
+	result, err := s.db.Exec("DELETE FROM scheduler WHERE id = ? AND user_id = ? AND deleted_at != ''", id, s.user)
+	if err != nil {
+		return err
+	}
+	if n, err := result.RowsAffected(); err == nil && n == 0 {
+		return store.ErrNoSuchTask
+	}
+	return nil
+}
+
+// PurgeTrash permanently deletes tasks moved to the trash before the given
+// time and returns how many there were. A zero time empties the trash.
+func (s *Storage) PurgeTrash(before time.Time) (int64, error) {
+	query := "DELETE FROM scheduler WHERE user_id = ? AND deleted_at != ''"
+	args := []any{s.user}
+	if !before.IsZero() {
+		query += " AND deleted_at < ?"
+		args = append(args, before.UTC().Format(time.RFC3339))
+	}
+	result, err := s.db.Exec(query, args...)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
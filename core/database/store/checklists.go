@@ -0,0 +1,21 @@
+package store
+
+import "main/internal/models/checklists"
+
+// CheckOrder returns ErrInvalidOrder unless ids name each of the items once.
+func CheckOrder(items []checklists.Item, ids []string) error {
+	if len(ids) != len(items) {
+		return ErrInvalidOrder
+	}
+	left := make(map[string]bool, len(items))
+	for _, item := range items {
+		left[item.ID] = true
+	}
+	for _, id := range ids {
+		if !left[id] {
+			return ErrInvalidOrder
+		}
+		delete(left, id)
+	}
+	return nil
+}
@@ -0,0 +1,21 @@
+package store
+
+import (
+	"fmt"
+	"main/internal/models/holidays"
+	"main/pkg"
+)
+
+// LoadHolidays imports holidays from a CSV or ICS file.
+func LoadHolidays(s HolidayStore, path string) error {
+	list, err := pkg.ReadHolidays(path)
+	if err != nil {
+		return fmt.Errorf("failed to read holidays: %w", err)
+	}
+	for _, holiday := range list {
+		if err = s.AddHoliday(holidays.Holiday{Date: holiday.Date.Format("20060102"), Name: holiday.Name}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,154 @@
+package store
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"main/internal/models/tasks"
+	"strconv"
+)
+
+// PageSize is the number of tasks returned by list and search calls that
+// don't ask for another number.
+const PageSize = 10
+
+// ErrInvalidCursor is returned for a cursor that is malformed or was made for
+// another order of the list.
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// Page selects a part of a task list.
+type Page struct {
+	// Limit is the number of tasks to return, PageSize if zero.
+	Limit  int
+	Offset int
+	// After continues the list after the last task of a previous page. Unlike
+	// an offset, it keeps its place when tasks before it are changed.
+	After *Cursor
+}
+
+// Size returns the number of tasks to return.
+func (p Page) Size() int {
+	if p.Limit <= 0 {
+		return PageSize
+	}
+	return p.Limit
+}
+
+// Cursor returns the cursor to continue after, checking that it was made for
+// the list sorted by sort. It is nil if the page has none.
+func (p Page) Cursor(sort string, desc bool) (*Cursor, error) {
+	if p.After == nil {
+		return nil, nil
+	}
+	if p.After.Sort != sort || p.After.Desc != desc || len(p.After.Key) != len(SortKey(sort, tasks.Task{})) {
+		return nil, ErrInvalidCursor
+	}
+	return p.After, nil
+}
+
+// Fields the task list can be sorted by. Tasks that sort equal are ordered by
+// id, which is the order they were created in.
+const (
+	SortDate  = "date"
+	SortTitle = "title"
+	SortID    = "id"
+	// SortRank orders search results by relevance. Its key is the rank
+	// the backend computes, best first. Ranks depend on all indexed tasks,
+	// so a cursor may repeat or skip results if tasks are added or edited
+	// between pages.
+	SortRank = "rank"
+)
+
+// SortKey returns the values a task is ordered by before its id.
+func SortKey(sort string, task tasks.Task) []string {
+	switch sort {
+	case SortDate:
+		return []string{task.Date, strconv.Itoa(task.Priority), task.Time}
+	case SortTitle:
+		return []string{task.Title}
+	case SortRank:
+		return []string{""}
+	}
+	return []string{}
+}
+
+// TaskQuery selects and orders the task list.
+type TaskQuery struct {
+	// From and To limit task dates inclusively. Either is empty when not set.
+	From string
+	To   string
+	// Recurring selects repeating tasks if true and one-off tasks if false.
+	Recurring *bool
+	// List selects the tasks of a list if it is not zero.
+	List int64
+	// Tag selects the tasks marked with a tag if it is not empty.
+	Tag string
+	// Priority selects the tasks with a priority if it is not zero.
+	Priority int
+	// Sort is the field to sort by, SortDate if empty. Tasks sorted by date
+	// are also sorted by priority, most urgent first, and then by time.
+	Sort string
+	Desc bool
+	Page
+}
+
+// SortField returns the field the list is sorted by.
+func (q TaskQuery) SortField() string {
+	if q.Sort == "" {
+		return SortDate
+	}
+	return q.Sort
+}
+
+// Cursor marks the place of a task in a sorted list.
+type Cursor struct {
+	Sort string   `json:"s"`
+	Desc bool     `json:"d,omitempty"`
+	Key  []string `json:"k"`
+	ID   int64    `json:"i"`
+}
+
+// CursorAt returns the cursor of a task in a list sorted by sort.
+func CursorAt(sort string, desc bool, task tasks.Task) *Cursor {
+	id, _ := strconv.ParseInt(task.ID, 10, 64)
+	return &Cursor{Sort: sort, Desc: desc, Key: SortKey(sort, task), ID: id}
+}
+
+// String returns the cursor as an opaque token for clients.
+func (c *Cursor) String() string {
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// ParseCursor reads a token made by Cursor.String.
+func ParseCursor(token string) (*Cursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	var cursor Cursor
+	if err = json.Unmarshal(data, &cursor); err != nil || cursor.Key == nil {
+		return nil, ErrInvalidCursor
+	}
+	return &cursor, nil
+}
+
+// TaskPage is a page of a task list.
+type TaskPage struct {
+	Tasks []tasks.Task
+	// Next is the cursor of the next page, nil on the last page.
+	Next *Cursor
+	// Total is the number of tasks on all pages.
+	Total int
+}
+
+// NewTaskPage makes a page of a list sorted by sort, which was fetched with a
+// task more than size to tell whether there is a next page.
+func NewTaskPage(list []tasks.Task, size, total int, sort string, desc bool) TaskPage {
+	page := TaskPage{Tasks: list, Total: total}
+	if len(list) > size {
+		page.Tasks = list[:size]
+		page.Next = CursorAt(sort, desc, list[size-1])
+	}
+	return page
+}
@@ -0,0 +1,85 @@
+package store
+
+import (
+	"main/core/config"
+	"main/internal/models/holidays"
+	"main/internal/models/tasks"
+	"main/pkg"
+	"time"
+)
+
+// ShiftTask moves a task scheduled on base off weekends and holidays if it
+// asks to be shifted, remembering base as its anchor.
+func ShiftTask(task *tasks.Task, base string, cal *pkg.Calendar) error {
+	task.Date, task.Anchor = base, ""
+	if task.Shift == "" {
+		return nil
+	}
+
+	date, err := time.Parse("20060102", base)
+	if err != nil {
+		return err
+	}
+	if shifted := cal.Shift(date, pkg.Shift(task.Shift)); !shifted.Equal(date) {
+		task.Date, task.Anchor = shifted.Format("20060102"), base
+	}
+	return nil
+}
+
+// RemainingRule returns the repeat rule of a task with its count reduced by
+// the occurrences before the current one, so that it counts from the task
+// date. It returns pkg.ErrRepeatFinished if no occurrences are left.
+func RemainingRule(task tasks.Task) (pkg.RepeatRule, error) {
+	rule, err := pkg.ParseRepeat(task.Repeat)
+	if err != nil {
+		return pkg.RepeatRule{}, err
+	}
+	if rule.Count > 0 && task.Occurrence > 1 {
+		rule.Count -= task.Occurrence - 1
+		if rule.Count < 1 {
+			return pkg.RepeatRule{}, pkg.ErrRepeatFinished
+		}
+	}
+	return rule, nil
+}
+
+// NextOccurrence returns the date a repeating task rolls forward to when it is
+// done, the unshifted date it was moved from if it is shifted to a business
+// day, and which occurrence of its series that is. Occurrences skipped on the
+// way count towards the rule's count limit.
+func NextOccurrence(task tasks.Task, cal *pkg.Calendar) (string, string, int, error) {
+	rule, err := RemainingRule(task)
+	if err != nil {
+		return "", "", 0, err
+	}
+	base := task.Date
+	if task.Anchor != "" {
+		base = task.Anchor
+	}
+	date, err := time.Parse("20060102", base)
+	if err != nil {
+		return "", "", 0, err
+	}
+	next, unshifted, steps, err := cal.Advance(rule, date, pkg.Today(config.Location()), pkg.Shift(task.Shift))
+	if err != nil {
+		return "", "", 0, err
+	}
+	anchor := ""
+	if !next.Equal(unshifted) {
+		anchor = unshifted.Format("20060102")
+	}
+	return next.Format("20060102"), anchor, task.Occurrence + steps, nil
+}
+
+// CalendarOf builds a business day calendar from a list of holidays.
+func CalendarOf(list []holidays.Holiday) (*pkg.Calendar, error) {
+	dates := make([]time.Time, 0, len(list))
+	for _, holiday := range list {
+		date, err := time.Parse("20060102", holiday.Date)
+		if err != nil {
+			return nil, err
+		}
+		dates = append(dates, date)
+	}
+	return pkg.NewCalendar(dates), nil
+}
@@ -0,0 +1,195 @@
+package store
+
+import (
+	"errors"
+	"fmt"
+	"html"
+	"main/internal/models/tasks"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// SearchTerm is a word or a quoted phrase of a search query. All terms of a
+// query must match, except excluded ones, which must not.
+type SearchTerm struct {
+	Text   string
+	Phrase bool
+	// Prefix is set for words written with a trailing *, which match any word
+	// starting with them.
+	Prefix bool
+	// Exclude is set for terms written with a leading -.
+	Exclude bool
+}
+
+// Filter is a parsed search query.
+type Filter struct {
+	Terms []SearchTerm
+	// After and Before limit task dates, After inclusively and Before
+	// exclusively. Either is empty when not set.
+	After  string
+	Before string
+	// Repeat selects repeating tasks if true and one-off tasks if false.
+	Repeat *bool
+	// Tags are the tags a task must all be marked with.
+	Tags []string
+	// Priority selects tasks with a priority if it is not zero.
+	Priority int
+}
+
+// Empty reports whether the filter has no conditions at all.
+func (f Filter) Empty() bool {
+	return len(f.Terms) == 0 && f.After == "" && f.Before == "" && f.Repeat == nil && len(f.Tags) == 0 && f.Priority == 0
+}
+
+// Matches returns the terms that must match, leaving out excluded ones.
+func (f Filter) Matches() []SearchTerm {
+	var result []SearchTerm
+	for _, term := range f.Terms {
+		if !term.Exclude {
+			result = append(result, term)
+		}
+	}
+	return result
+}
+
+// Excludes returns the terms that must not match.
+func (f Filter) Excludes() []SearchTerm {
+	var result []SearchTerm
+	for _, term := range f.Terms {
+		if term.Exclude {
+			result = append(result, term)
+		}
+	}
+	return result
+}
+
+// Backends mark matches in snippets with these, and Highlight turns them into
+// HTML once the rest of the snippet is escaped.
+const (
+	MatchStart = "\x02"
+	MatchEnd   = "\x03"
+)
+
+// ParseFilter parses a search query of words, "quoted phrases" and
+// key:value filters:
+//
+//	before:20261101 after:20261001 repeat:yes tag:work priority:1 "exact phrase" -excluded
+//
+// A leading - excludes a word or phrase. Words with a key that is not a
+// filter, such as 18:00, are searched as text. Terms without a letter or digit
+// are dropped since they can't match anything.
+func ParseFilter(search string) (Filter, error) {
+	var filter Filter
+	add := func(term SearchTerm) {
+		if strings.IndexFunc(term.Text, func(r rune) bool {
+			return unicode.IsLetter(r) || unicode.IsDigit(r)
+		}) >= 0 {
+			filter.Terms = append(filter.Terms, term)
+		}
+	}
+
+	for search = strings.TrimSpace(search); search != ""; search = strings.TrimSpace(search) {
+		exclude := strings.HasPrefix(search, "-")
+		if exclude {
+			search = search[1:]
+		}
+		if strings.HasPrefix(search, `"`) {
+			phrase, rest, _ := strings.Cut(search[1:], `"`)
+			add(SearchTerm{Text: strings.Join(strings.Fields(phrase), " "), Phrase: true, Exclude: exclude})
+			search = rest
+			continue
+		}
+		end := strings.IndexFunc(search, func(r rune) bool {
+			return unicode.IsSpace(r) || r == '"'
+		})
+		if end < 0 {
+			end = len(search)
+		}
+		word := search[:end]
+		search = search[end:]
+
+		if key, value, ok := strings.Cut(word, ":"); ok && !exclude {
+			isFilter, err := filter.set(strings.ToLower(key), value)
+			if err != nil {
+				return Filter{}, err
+			}
+			if isFilter {
+				continue
+			}
+		}
+		prefix := strings.HasSuffix(word, "*")
+		add(SearchTerm{Text: strings.TrimRight(word, "*"), Prefix: prefix, Exclude: exclude})
+	}
+	return filter, nil
+}
+
+// set applies a key:value filter and reports whether key is a filter.
+func (f *Filter) set(key, value string) (bool, error) {
+	switch key {
+	case "before", "after":
+		date, err := parseFilterDate(value)
+		if err != nil {
+			return true, fmt.Errorf("invalid date %q in %s:", value, key)
+		}
+		if key == "before" {
+			f.Before = date
+		} else {
+			f.After = date
+		}
+	case "repeat":
+		var repeat bool
+		switch strings.ToLower(value) {
+		case "yes":
+			repeat = true
+		case "no":
+		default:
+			return true, fmt.Errorf("repeat: must be yes or no, not %q", value)
+		}
+		f.Repeat = &repeat
+	case "tag":
+		tag, err := NormalizeTag(value)
+		if err != nil {
+			return true, fmt.Errorf("invalid tag: %w", err)
+		}
+		if !slices.Contains(f.Tags, tag) {
+			f.Tags = append(f.Tags, tag)
+		}
+	case "priority":
+		priority, err := ParsePriority(value)
+		if err != nil {
+			return true, fmt.Errorf("invalid priority: %w", err)
+		}
+		f.Priority = priority
+	default:
+		return false, nil
+	}
+	return true, nil
+}
+
+// ParsePriority reads a task priority from PriorityHighest to PriorityLowest.
+func ParsePriority(value string) (int, error) {
+	priority, err := strconv.Atoi(value)
+	if err != nil || priority < tasks.PriorityHighest || priority > tasks.PriorityLowest {
+		return 0, fmt.Errorf("priority must be from %d to %d, not %q", tasks.PriorityHighest, tasks.PriorityLowest, value)
+	}
+	return priority, nil
+}
+
+func parseFilterDate(value string) (string, error) {
+	for _, layout := range []string{"20060102", "02.01.2006"} {
+		if date, err := time.Parse(layout, value); err == nil {
+			return date.Format("20060102"), nil
+		}
+	}
+	return "", errors.New("invalid date")
+}
+
+// Highlight escapes a snippet for HTML and wraps matches in <mark> tags.
+func Highlight(snippet string) string {
+	snippet = html.EscapeString(snippet)
+	snippet = strings.ReplaceAll(snippet, MatchStart, "<mark>")
+	return strings.ReplaceAll(snippet, MatchEnd, "</mark>")
+}
@@ -0,0 +1,203 @@
+// Package store defines the storage interface the controllers work with, so
+// that the backend can be chosen by configuration.
+package store
+
+import (
+	"errors"
+	"main/internal/models/attachments"
+	"main/internal/models/checklists"
+	"main/internal/models/completions"
+	"main/internal/models/holidays"
+	"main/internal/models/lists"
+	"main/internal/models/tags"
+	"main/internal/models/tasks"
+	"main/internal/models/users"
+	"main/pkg"
+	"time"
+)
+
+var (
+	ErrNoSuchTask    = errors.New("no such task")
+	ErrNoSuchHoliday = errors.New("no such holiday")
+	ErrNoSuchUser    = errors.New("no such user")
+	ErrUserExists    = errors.New("user already exists")
+	ErrNoSuchList    = errors.New("no such list")
+	ErrListExists    = errors.New("list already exists")
+	ErrNoSuchTag     = errors.New("no such tag")
+	ErrTagExists     = errors.New("tag already exists")
+	ErrNoSuchItem    = errors.New("no such checklist item")
+	// ErrInvalidOrder is returned when a new order of a checklist does not
+	// name each of its items once.
+	ErrInvalidOrder = errors.New("order must list every checklist item once")
+	// ErrTaskBlocked is returned by DoneTask for a task with open blockers.
+	ErrTaskBlocked      = errors.New("task is blocked by open tasks")
+	ErrDependencyCycle  = errors.New("dependency would make a cycle")
+	ErrNoSuchDependency = errors.New("no such dependency")
+	ErrNoSuchAttachment = errors.New("no such attachment")
+)
+
+// Owner is the user id of the administrator, who owns every task of a
+// single-user installation.
+const Owner int64 = 0
+
+type TaskStore interface {
+	// AddTaskDB adds a task, to the list given by its ListID if it is set.
+	// Tags the user has not used yet are created.
+	AddTaskDB(task tasks.Task) (int64, error)
+	FindTask(id string) (*tasks.Task, error)
+	UpdateTask(task tasks.Task) error
+	Tasks(query TaskQuery) (TaskPage, error)
+	// SearchTasks returns tasks matching the filter, or none if it is empty.
+	// Tasks matched by text may be sorted by rank.
+	SearchTasks(filter Filter, page Page) (TaskPage, error)
+	TasksByDate(date string) ([]tasks.Task, error)
+	// TasksUntil returns every task scheduled on or before the given date,
+	// which is every task that can have an occurrence up to that date.
+	TasksUntil(date string) ([]tasks.Task, error)
+	// DoneTask rolls a repeating task forward to its next occurrence,
+	// unchecking its checklist, or moves a one-off task to the trash,
+	// recording the completion with an optional note. It returns
+	// ErrTaskBlocked while the task has open blockers.
+	DoneTask(id string, note string) error
+	// DeleteTask moves a task to the trash.
+	DeleteTask(id string) error
+	// MoveTask moves a task to a list, or out of any list if list is zero.
+	MoveTask(id string, list int64) error
+}
+
+// ListStore keeps the lists a user sorts tasks into. Each task is in one list
+// at most.
+type ListStore interface {
+	// Lists returns the lists ordered by name, each with the number of tasks
+	// in it.
+	Lists() ([]lists.List, error)
+	AddList(list lists.List) (int64, error)
+	// UpdateList renames a list.
+	UpdateList(list lists.List) error
+	// DeleteList deletes a list, leaving its tasks out of any list.
+	DeleteList(id string) error
+}
+
+// TagStore keeps the tags a user marks tasks with. Tag names are normalized
+// with NormalizeTag by the caller.
+type TagStore interface {
+	// Tags returns the tags ordered by name, each with the number of tasks
+	// marked with it.
+	Tags() ([]tags.Tag, error)
+	AddTag(tag tags.Tag) (int64, error)
+	// UpdateTag renames a tag.
+	UpdateTag(tag tags.Tag) error
+	// MergeTags marks the tasks of tag from with tag into and deletes from.
+	MergeTags(from string, into string) error
+	// DeleteTag deletes a tag, removing it from its tasks.
+	DeleteTag(id string) error
+}
+
+// ChecklistStore keeps the checklists of tasks. Items of a task in the trash
+// can't be seen or changed until it is restored.
+type ChecklistStore interface {
+	// Checklist returns the items of a task in order.
+	Checklist(task string) ([]checklists.Item, error)
+	// AddItem adds an unchecked item to the end of a task's checklist.
+	AddItem(task string, text string) (int64, error)
+	// ReorderChecklist puts the items of a task's checklist in the order of
+	// ids, which must name each item once.
+	ReorderChecklist(task string, ids []string) error
+	// ToggleItem checks an item or unchecks a checked one and returns whether
+	// it is now checked.
+	ToggleItem(id string) (bool, error)
+	DeleteItem(id string) error
+}
+
+// DependencyStore keeps which tasks block others. A task is blocked while any
+// of its blockers is open: a one-off blocker until it is done or deleted, and
+// a repeating one until it moves past the date of the blocked task. Tasks
+// returned by the other stores have Blocked set accordingly.
+type DependencyStore interface {
+	// Blockers returns the tasks a task is blocked by, open or not, leaving
+	// out the ones in the trash.
+	Blockers(id string) ([]tasks.Task, error)
+	// AddDependency makes a task blocked by another. It returns
+	// ErrDependencyCycle if the blocker is, maybe indirectly, blocked by the
+	// task itself.
+	AddDependency(id string, blocker string) error
+	DeleteDependency(id string, blocker string) error
+}
+
+// AttachmentStore keeps what is known about the files attached to tasks; the
+// files themselves are kept by the caller. Attachments of a task in the trash
+// can't be seen or changed until it is restored, and are kept until it is
+// purged.
+type AttachmentStore interface {
+	// Attachments returns the attachments of a task, oldest first.
+	Attachments(task string) ([]attachments.Attachment, error)
+	// AddAttachment attaches a file to the task given by its TaskID.
+	AddAttachment(attachment attachments.Attachment) (int64, error)
+	FindAttachment(id string) (*attachments.Attachment, error)
+	DeleteAttachment(id string) error
+	// PurgeAttachments forgets the attachments of tasks that no longer
+	// exist, whoever they belonged to, and returns them so that their files
+	// can be removed.
+	PurgeAttachments() ([]attachments.Attachment, error)
+}
+
+type TrashStore interface {
+	// Trash returns deleted tasks, most recently deleted first.
+	Trash() ([]tasks.Task, error)
+	RestoreTask(id string) error
+	// PurgeTask permanently deletes a task from the trash.
+	PurgeTask(id string) error
+	// PurgeTrash permanently deletes tasks moved to the trash before the
+	// given time and returns how many there were. A zero time empties the
+	// trash.
+	PurgeTrash(before time.Time) (int64, error)
+}
+
+type CompletionStore interface {
+	// TaskHistory returns the completions of a task, oldest first. It is kept
+	// after the task itself is deleted.
+	TaskHistory(id string) ([]completions.Completion, error)
+	// Completions returns the completions made in [from, to), oldest first. A
+	// zero bound leaves that side open.
+	Completions(from time.Time, to time.Time) ([]completions.Completion, error)
+}
+
+type HolidayStore interface {
+	Holidays() ([]holidays.Holiday, error)
+	AddHoliday(holiday holidays.Holiday) error
+	DeleteHoliday(date string) error
+	// Calendar returns the business day calendar built from stored holidays.
+	Calendar() (*pkg.Calendar, error)
+}
+
+// UserStore keeps user accounts. Accounts are shared by every user's view of
+// the store.
+type UserStore interface {
+	// AddUser creates an account with a hashed password and returns its id.
+	AddUser(user users.User) (int64, error)
+	FindUser(id int64) (*users.User, error)
+	FindUserByLogin(login string) (*users.User, error)
+	Users() ([]users.User, error)
+	// DeleteUser deletes an account with all its tasks, lists, tags and
+	// completions.
+	DeleteUser(id int64) error
+}
+
+// Store is everything a storage backend provides.
+type Store interface {
+	TaskStore
+	ChecklistStore
+	DependencyStore
+	AttachmentStore
+	TrashStore
+	CompletionStore
+	ListStore
+	TagStore
+	HolidayStore
+	UserStore
+	// ForUser returns the store as seen by a user, whose tasks, trash, lists,
+	// tags and completions are kept apart from other users'. Holidays are
+	// shared. The store a backend is opened with belongs to Owner.
+	ForUser(id int64) Store
+	Close() error
+}
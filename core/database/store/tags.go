@@ -0,0 +1,48 @@
+package store
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// MaxTagLength is the longest tag name in characters.
+const MaxTagLength = 32
+
+// NormalizeTag lowercases a tag name and checks that it is a single word, so
+// that it can be written in a tag: search filter.
+func NormalizeTag(name string) (string, error) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if name == "" {
+		return "", fmt.Errorf("empty tag")
+	}
+	if utf8.RuneCountInString(name) > MaxTagLength {
+		return "", fmt.Errorf("tag %q is longer than %d characters", name, MaxTagLength)
+	}
+	if strings.IndexFunc(name, func(r rune) bool {
+		return unicode.IsSpace(r) || r == '"' || r == ','
+	}) >= 0 {
+		return "", fmt.Errorf("tag %q cannot contain spaces, commas or quotes", name)
+	}
+	return name, nil
+}
+
+// NormalizeTags normalizes tag names, sorting them and dropping repeats. A nil
+// list stays nil, so that it can mean that tags are not given.
+func NormalizeTags(names []string) ([]string, error) {
+	if names == nil {
+		return nil, nil
+	}
+	result := make([]string, 0, len(names))
+	for _, name := range names {
+		tag, err := NormalizeTag(name)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, tag)
+	}
+	slices.Sort(result)
+	return slices.Compact(result), nil
+}
@@ -0,0 +1,71 @@
+// Package files keeps the files attached to tasks in the configured
+// directory. Their names are generated, so that nothing a user uploads can
+// choose where it is written.
+package files
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"go.uber.org/zap"
+	"io"
+	"io/fs"
+	"main/core/config"
+	"main/core/database/store"
+	"main/core/logger"
+	"os"
+	"path/filepath"
+)
+
+// Save writes the contents of r to a new file and returns its name.
+func Save(r io.Reader) (string, error) {
+	if err := os.MkdirAll(config.Get().Attachments.Dir, 0o755); err != nil {
+		return "", err
+	}
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	name := hex.EncodeToString(b)
+	f, err := os.OpenFile(Path(name), os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o644)
+	if err != nil {
+		return "", err
+	}
+	if _, err = io.Copy(f, r); err != nil {
+		f.Close()
+		os.Remove(Path(name))
+		return "", err
+	}
+	if err = f.Close(); err != nil {
+		os.Remove(Path(name))
+		return "", err
+	}
+	return name, nil
+}
+
+// Path returns where the file with the given name is kept.
+func Path(name string) string {
+	return filepath.Join(config.Get().Attachments.Dir, name)
+}
+
+// Remove deletes a file. A file that is already gone is not an error.
+func Remove(name string) error {
+	if err := os.Remove(Path(name)); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+// Purge removes the files of tasks that have been permanently deleted.
+func Purge(s store.Store) {
+	list, err := s.PurgeAttachments()
+	if err != nil {
+		logger.Get().Error("failed to purge attachments", zap.Error(err))
+		return
+	}
+	for _, attachment := range list {
+		if err = Remove(attachment.File); err != nil {
+			logger.Get().Error("failed to remove attachment", zap.String("file", attachment.File), zap.Error(err))
+		}
+	}
+}
@@ -0,0 +1,63 @@
+package jobs
+
+import (
+	"go.uber.org/zap"
+	"main/core/config"
+	"main/core/database"
+	"main/core/database/store"
+	"main/core/files"
+	"main/core/logger"
+	"strconv"
+	"time"
+)
+
+const trashPurgeInterval = time.Hour
+
+// StartTrashPurge permanently deletes tasks that have been in the trash for
+// longer than the configured retention period, once at start and then every
+// hour. A retention of zero days keeps trashed tasks until purged by hand.
+func StartTrashPurge() {
+	days := config.Get().Trash.RetentionDays
+	if days <= 0 {
+		return
+	}
+	go func() {
+		for {
+			purgeTrash(days)
+			time.Sleep(trashPurgeInterval)
+		}
+	}()
+}
+
+// purgeTrash empties the trash of the owner and of every user and removes the
+// files attached to the purged tasks.
+func purgeTrash(days int) {
+	list, err := database.Get().Users()
+	if err != nil {
+		logger.Get().Error("failed to purge trash", zap.Error(err))
+		return
+	}
+	ids := []int64{store.Owner}
+	for _, user := range list {
+		id, err := strconv.ParseInt(user.ID, 10, 64)
+		if err != nil {
+			continue
+		}
+		ids = append(ids, id)
+	}
+
+	before := time.Now().AddDate(0, 0, -days)
+	var n int64
+	for _, id := range ids {
+		purged, err := database.Get().ForUser(id).PurgeTrash(before)
+		if err != nil {
+			logger.Get().Error("failed to purge trash", zap.Int64("user", id), zap.Error(err))
+			continue
+		}
+		n += purged
+	}
+	files.Purge(database.Get())
+	if n > 0 {
+		logger.Get().Info("trash purged", zap.Int64("tasks", n))
+	}
+}
@@ -1,10 +1,14 @@
 package middleware
 
 import (
+	"errors"
 	"fmt"
 	"github.com/gofiber/fiber/v2"
 	"github.com/golang-jwt/jwt/v5"
 	"main/core/config"
+	"main/core/database"
+	"main/core/database/store"
+	"strconv"
 	"time"
 )
 
@@ -14,30 +18,76 @@ func init() {
 	secret = []byte(config.Get().Auth.Key)
 }
 
+const claimsKey = "claims"
+
+// Claims identify the user a token was issued to. The admin signs in with
+// TODO_PASSWORD and works with the tasks of store.Owner.
 type Claims struct {
-	Hash string `json:"hash"`
-	jwt.Claims
+	UserID int64 `json:"uid"`
+	Admin  bool  `json:"admin"`
+	jwt.RegisteredClaims
 }
 
+// AuthMiddleware checks the token cookie and keeps its claims for the
+// handlers. If TODO_PASSWORD is empty, a request without a token is let
+// through as store.Owner.
 func AuthMiddleware(c *fiber.Ctx) error {
-	if config.Get().Auth.Password != "" {
-		token := c.Cookies("token")
-		if token == "" {
+	token := c.Cookies("token")
+	if token == "" {
+		if config.Get().Auth.Password != "" {
 			return c.SendStatus(fiber.StatusUnauthorized)
 		}
-		if ok, _ := ValidateToken(token); !ok {
+		return c.Next()
+	}
+
+	claims, err := ValidateToken(token)
+	if err != nil {
+		return c.SendStatus(fiber.StatusUnauthorized)
+	}
+	// Tokens of deleted users stop working before they expire.
+	if claims.UserID != store.Owner {
+		if _, err = database.Get().FindUser(claims.UserID); errors.Is(err, store.ErrNoSuchUser) {
 			return c.SendStatus(fiber.StatusUnauthorized)
+		} else if err != nil {
+			return c.SendStatus(fiber.StatusInternalServerError)
+		}
+	}
+	c.Locals(claimsKey, claims)
+	return c.Next()
+}
+
+// AdminMiddleware lets through requests with an admin token only, or
+// requests without a token when authentication is off. It goes after
+// AuthMiddleware.
+func AdminMiddleware(c *fiber.Ctx) error {
+	claims, ok := c.Locals(claimsKey).(*Claims)
+	if !ok {
+		if config.Get().Auth.Password == "" {
+			return c.Next()
 		}
+		return c.SendStatus(fiber.StatusUnauthorized)
+	}
+	if !claims.Admin {
+		return c.SendStatus(fiber.StatusForbidden)
 	}
 	return c.Next()
 }
 
-func GenerateToken(hash string) (string, error) {
-	exp := time.Now().Add(8 * time.Hour)
+// UserID returns the id of the user making the request.
+func UserID(c *fiber.Ctx) int64 {
+	if claims, ok := c.Locals(claimsKey).(*Claims); ok {
+		return claims.UserID
+	}
+	return store.Owner
+}
+
+func GenerateToken(userID int64, admin bool) (string, error) {
 	claims := &Claims{
-		Hash: hash,
-		Claims: jwt.MapClaims{
-			"exp": exp,
+		UserID: userID,
+		Admin:  admin,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Subject:   strconv.FormatInt(userID, 10),
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(8 * time.Hour)),
 		},
 	}
 	t := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
@@ -48,7 +98,7 @@ func GenerateToken(hash string) (string, error) {
 	return token, nil
 }
 
-func ValidateToken(t string) (bool, error) {
+func ValidateToken(t string) (*Claims, error) {
 	claims := &Claims{}
 
 	token, err := jwt.ParseWithClaims(t, claims, func(token *jwt.Token) (interface{}, error) {
@@ -58,10 +108,10 @@ func ValidateToken(t string) (bool, error) {
 		return secret, nil
 	})
 	if err != nil {
-		return false, err
+		return nil, err
 	}
 	if !token.Valid {
-		return false, nil
+		return nil, errors.New("invalid token")
 	}
-	return true, nil
+	return claims, nil
 }
@@ -0,0 +1,101 @@
+package controllers
+
+import (
+	"errors"
+	"github.com/gofiber/fiber/v2"
+	"go.uber.org/zap"
+	"main/core/config"
+	"main/core/database/store"
+	"main/core/logger"
+	"main/internal/models/common"
+	"main/internal/models/tasks"
+	"main/pkg"
+	"sort"
+	"time"
+)
+
+const (
+	defaultAgendaDays = 7
+	maxAgendaDays     = 366
+)
+
+func GetAgenda(c *fiber.Ctx) error {
+	from := pkg.Today(config.Location())
+	if c.Query("from") != "" {
+		parsed, err := time.Parse("20060102", c.Query("from"))
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(common.ErrorResponse{Error: "invalid from date"})
+		}
+		from = parsed
+	}
+	to := from.AddDate(0, 0, defaultAgendaDays-1)
+	if c.Query("to") != "" {
+		parsed, err := time.Parse("20060102", c.Query("to"))
+		if err != nil || parsed.Before(from) {
+			return c.Status(fiber.StatusBadRequest).JSON(common.ErrorResponse{Error: "invalid to date"})
+		}
+		to = parsed
+	}
+	if to.After(from.AddDate(0, 0, maxAgendaDays-1)) {
+		return c.Status(fiber.StatusBadRequest).JSON(common.ErrorResponse{Error: "date range is too long"})
+	}
+
+	resultTasks, err := storeOf(c).TasksUntil(to.Format("20060102"))
+	if err != nil {
+		logger.Get().Info("cannot get tasks", zap.Error(err))
+		return c.Status(fiber.StatusInternalServerError).JSON(common.ErrorResponse{Error: "cannot get tasks"})
+	}
+
+	cal, err := storeOf(c).Calendar()
+	if err != nil {
+		logger.Get().Error("cannot load holidays", zap.Error(err))
+		return c.Status(fiber.StatusInternalServerError).JSON(common.ErrorResponse{Error: "cannot load holidays"})
+	}
+
+	days := make(map[string][]tasks.Task)
+	for _, task := range resultTasks {
+		// Shifted tasks are expanded from their original date, so the series
+		// doesn't drift with the shift.
+		base := task.Date
+		if task.Anchor != "" {
+			base = task.Anchor
+		}
+		// Occurrences already done don't count towards the rule's count.
+		repeat := task.Repeat
+		if repeat != "" {
+			rule, err := store.RemainingRule(task)
+			if errors.Is(err, pkg.ErrRepeatFinished) {
+				continue
+			}
+			if err != nil {
+				logger.Get().Info("cannot expand task", zap.String("id", task.ID), zap.Error(err))
+				continue
+			}
+			repeat = rule.String()
+		}
+		dates, err := cal.ShiftedOccurrences(base, repeat, from, to, 0, pkg.Shift(task.Shift))
+		if err != nil {
+			logger.Get().Info("cannot expand task", zap.String("id", task.ID), zap.Error(err))
+			continue
+		}
+		for _, date := range dates {
+			key := date.Format("20060102")
+			days[key] = append(days[key], task)
+		}
+	}
+
+	result := common.AgendaResponse{Days: make([]common.AgendaDay, 0, len(days))}
+	for date, dayTasks := range days {
+		sort.SliceStable(dayTasks, func(i, j int) bool {
+			if dayTasks[i].Priority != dayTasks[j].Priority {
+				return dayTasks[i].Priority < dayTasks[j].Priority
+			}
+			return dayTasks[i].Time < dayTasks[j].Time
+		})
+		result.Days = append(result.Days, common.AgendaDay{Date: date, Tasks: dayTasks})
+	}
+	sort.Slice(result.Days, func(i, j int) bool {
+		return result.Days[i].Date < result.Days[j].Date
+	})
+	return c.Status(fiber.StatusOK).JSON(result)
+}
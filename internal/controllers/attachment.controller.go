@@ -0,0 +1,174 @@
+package controllers
+
+import (
+	"errors"
+	"github.com/gofiber/fiber/v2"
+	"go.uber.org/zap"
+	"main/core/database/store"
+	"main/core/files"
+	"main/core/logger"
+	"main/internal/models/attachments"
+	"main/internal/models/common"
+	"mime"
+	"os"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+const maxAttachmentName = 255
+
+// safeContentTypes are the types attachments are sent with as uploaded. Any
+// other type, HTML and SVG in particular, is sent as a stream of bytes, so
+// that a browser never renders an uploaded file as a page of the app.
+var safeContentTypes = map[string]bool{
+	"application/pdf": true,
+	"image/gif":       true,
+	"image/jpeg":      true,
+	"image/png":       true,
+	"image/webp":      true,
+	"text/plain":      true,
+}
+
+// attachmentContentType returns the type an attachment uploaded as
+// contentType is sent with.
+func attachmentContentType(contentType string) string {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil || !safeContentTypes[mediaType] {
+		return fiber.MIMEOctetStream
+	}
+	return mediaType
+}
+
+// GetAttachments returns the attachments of the task id, oldest first.
+func GetAttachments(c *fiber.Ctx) error {
+	id := c.Query("id")
+	if id == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(common.ErrorResponse{Error: "id required"})
+	}
+	list, err := storeOf(c).Attachments(id)
+	if err != nil {
+		if errors.Is(err, store.ErrNoSuchTask) {
+			return c.Status(fiber.StatusBadRequest).JSON(common.ErrorResponse{Error: "no such task"})
+		}
+		logger.Get().Error("cannot get attachments", zap.Error(err))
+		return c.Status(fiber.StatusInternalServerError).JSON(common.ErrorResponse{Error: "cannot get attachments"})
+	}
+	if list == nil {
+		list = []attachments.Attachment{}
+	}
+	return c.Status(fiber.StatusOK).JSON(common.AttachmentsResponse{Attachments: list})
+}
+
+// AddAttachment attaches the file sent as the multipart field "file" to the
+// task id. Fiber rejects requests over FIBER_BODY_LIMIT bytes before they get
+// here, which limits the size of the file.
+func AddAttachment(c *fiber.Ctx) error {
+	id := c.Query("id")
+	if id == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(common.ErrorResponse{Error: "id required"})
+	}
+	task, err := strconv.ParseInt(id, 10, 64)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(common.ErrorResponse{Error: "no such task"})
+	}
+	header, err := c.FormFile("file")
+	if err != nil {
+		logger.Get().Info("cannot get file", zap.Error(err))
+		return c.Status(fiber.StatusBadRequest).JSON(common.ErrorResponse{Error: "file required"})
+	}
+	name := strings.TrimSpace(header.Filename)
+	if name == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(common.ErrorResponse{Error: "file name required"})
+	}
+	if len(name) > maxAttachmentName || !utf8.ValidString(name) {
+		return c.Status(fiber.StatusBadRequest).JSON(common.ErrorResponse{Error: "incorrect file name"})
+	}
+	contentType := header.Header.Get(fiber.HeaderContentType)
+	if contentType == "" || len(contentType) > maxAttachmentName {
+		contentType = fiber.MIMEOctetStream
+	}
+
+	f, err := header.Open()
+	if err != nil {
+		logger.Get().Error("cannot open uploaded file", zap.Error(err))
+		return c.Status(fiber.StatusInternalServerError).JSON(common.ErrorResponse{Error: "cannot add attachment"})
+	}
+	defer f.Close()
+	file, err := files.Save(f)
+	if err != nil {
+		logger.Get().Error("cannot save attachment", zap.Error(err))
+		return c.Status(fiber.StatusInternalServerError).JSON(common.ErrorResponse{Error: "cannot add attachment"})
+	}
+	attachment, err := storeOf(c).AddAttachment(attachments.Attachment{
+		TaskID:      task,
+		Name:        name,
+		Size:        header.Size,
+		ContentType: contentType,
+		File:        file,
+	})
+	if err != nil {
+		if removeErr := files.Remove(file); removeErr != nil {
+			logger.Get().Error("cannot remove attachment", zap.String("file", file), zap.Error(removeErr))
+		}
+		if errors.Is(err, store.ErrNoSuchTask) {
+			return c.Status(fiber.StatusBadRequest).JSON(common.ErrorResponse{Error: "no such task"})
+		}
+		logger.Get().Error("cannot add attachment", zap.Error(err))
+		return c.Status(fiber.StatusInternalServerError).JSON(common.ErrorResponse{Error: "cannot add attachment"})
+	}
+	return c.Status(fiber.StatusOK).JSON(common.SuccessResponse{Id: int(attachment)})
+}
+
+// GetAttachmentFile sends the file of the attachment given by the attachment
+// parameter for download under its original name. Browsers are told not to
+// guess the type of the file, and types outside safeContentTypes are sent as
+// application/octet-stream.
+func GetAttachmentFile(c *fiber.Ctx) error {
+	id := c.Query("attachment")
+	if id == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(common.ErrorResponse{Error: "attachment required"})
+	}
+	attachment, err := storeOf(c).FindAttachment(id)
+	if err != nil {
+		if errors.Is(err, store.ErrNoSuchAttachment) {
+			return c.Status(fiber.StatusBadRequest).JSON(common.ErrorResponse{Error: err.Error()})
+		}
+		logger.Get().Error("cannot find attachment", zap.Error(err))
+		return c.Status(fiber.StatusInternalServerError).JSON(common.ErrorResponse{Error: "cannot get attachment"})
+	}
+	f, err := os.Open(files.Path(attachment.File))
+	if err != nil {
+		logger.Get().Error("cannot open attachment", zap.String("file", attachment.File), zap.Error(err))
+		return c.Status(fiber.StatusInternalServerError).JSON(common.ErrorResponse{Error: "cannot get attachment"})
+	}
+	c.Attachment(attachment.Name)
+	c.Set(fiber.HeaderContentType, attachmentContentType(attachment.ContentType))
+	c.Set(fiber.HeaderXContentTypeOptions, "nosniff")
+	// The stream is closed once it has been sent.
+	return c.Status(fiber.StatusOK).SendStream(f, int(attachment.Size))
+}
+
+// DeleteAttachment deletes the attachment given by the attachment parameter
+// together with its file.
+func DeleteAttachment(c *fiber.Ctx) error {
+	id := c.Query("attachment")
+	if id == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(common.ErrorResponse{Error: "attachment required"})
+	}
+	attachment, err := storeOf(c).FindAttachment(id)
+	if err == nil {
+		err = storeOf(c).DeleteAttachment(id)
+	}
+	if err != nil {
+		if errors.Is(err, store.ErrNoSuchAttachment) {
+			return c.Status(fiber.StatusBadRequest).JSON(common.ErrorResponse{Error: err.Error()})
+		}
+		logger.Get().Error("cannot delete attachment", zap.Error(err))
+		return c.Status(fiber.StatusInternalServerError).JSON(common.ErrorResponse{Error: "cannot delete attachment"})
+	}
+	if err = files.Remove(attachment.File); err != nil {
+		logger.Get().Error("cannot remove attachment", zap.String("file", attachment.File), zap.Error(err))
+	}
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{})
+}
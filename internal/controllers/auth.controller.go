@@ -1,29 +1,58 @@
 package controllers
 
 import (
+	"errors"
 	"github.com/gofiber/fiber/v2"
 	"go.uber.org/zap"
+	"golang.org/x/crypto/bcrypt"
 	"main/core/config"
+	"main/core/database"
+	"main/core/database/store"
 	"main/core/logger"
 	"main/core/middleware"
 	"main/internal/models/common"
 )
 
+// SignIn issues a token to a user signing in with a login and password, or
+// to the admin signing in with TODO_PASSWORD and no login.
 func SignIn(c *fiber.Ctx) error {
-	var password struct {
+	var body struct {
+		Login    string `json:"login"`
 		Password string `json:"password" binding:"required"`
 	}
-	if err := c.BodyParser(&password); err != nil {
+	if err := c.BodyParser(&body); err != nil {
 		return c.Status(fiber.StatusBadRequest).JSON(common.ErrorResponse{Error: "invalid request"})
 	}
-	if password.Password != config.Get().Auth.Password || password.Password == "" {
-		return c.Status(fiber.StatusBadRequest).JSON(common.ErrorResponse{Error: "incorrect password"})
+
+	userID, admin := store.Owner, true
+	if body.Login == "" {
+		if body.Password != config.Get().Auth.Password || body.Password == "" {
+			return c.Status(fiber.StatusBadRequest).JSON(common.ErrorResponse{Error: "incorrect password"})
+		}
+	} else {
+		user, err := database.Get().FindUserByLogin(body.Login)
+		if err != nil && !errors.Is(err, store.ErrNoSuchUser) {
+			logger.Get().Error("cannot find user", zap.Error(err))
+			return c.Status(fiber.StatusInternalServerError).JSON(common.ErrorResponse{Error: "cannot sign in"})
+		}
+		if err != nil || bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(body.Password)) != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(common.ErrorResponse{Error: "incorrect login or password"})
+		}
+		if userID, err = parseUserID(user.ID); err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(common.ErrorResponse{Error: "cannot sign in"})
+		}
+		admin = false
 	}
 
-	token, err := middleware.GenerateToken(password.Password)
+	token, err := middleware.GenerateToken(userID, admin)
 	if err != nil {
 		logger.Get().Error("failed to generate token", zap.Error(err))
 		return c.Status(fiber.StatusInternalServerError).JSON(common.ErrorResponse{Error: "failed to generate token"})
 	}
 	return c.Status(fiber.StatusOK).JSON(fiber.Map{"token": token})
 }
+
+// storeOf returns the store as seen by the user making the request.
+func storeOf(c *fiber.Ctx) store.Store {
+	return database.Get().ForUser(middleware.UserID(c))
+}
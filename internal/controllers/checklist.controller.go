@@ -0,0 +1,118 @@
+package controllers
+
+import (
+	"errors"
+	"github.com/gofiber/fiber/v2"
+	"go.uber.org/zap"
+	"main/core/database/store"
+	"main/core/logger"
+	"main/internal/models/checklists"
+	"main/internal/models/common"
+	"strings"
+	"unicode/utf8"
+)
+
+const maxItemText = 256
+
+// GetChecklist returns the checklist of the task id in order.
+func GetChecklist(c *fiber.Ctx) error {
+	id := c.Query("id")
+	if id == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(common.ErrorResponse{Error: "id required"})
+	}
+	items, err := storeOf(c).Checklist(id)
+	if err != nil {
+		if errors.Is(err, store.ErrNoSuchTask) {
+			return c.Status(fiber.StatusBadRequest).JSON(common.ErrorResponse{Error: "no such task"})
+		}
+		logger.Get().Error("cannot get checklist", zap.Error(err))
+		return c.Status(fiber.StatusInternalServerError).JSON(common.ErrorResponse{Error: "cannot get checklist"})
+	}
+	if items == nil {
+		items = []checklists.Item{}
+	}
+	return c.Status(fiber.StatusOK).JSON(common.ChecklistResponse{Items: items})
+}
+
+// AddItem adds an item to the end of the checklist of the task id.
+func AddItem(c *fiber.Ctx) error {
+	id := c.Query("id")
+	if id == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(common.ErrorResponse{Error: "id required"})
+	}
+	var body common.ChecklistItem
+	if err := c.BodyParser(&body); err != nil {
+		logger.Get().Info("cannot parse body", zap.Error(err))
+		return c.Status(fiber.StatusBadRequest).JSON(common.ErrorResponse{Error: "incorrect request"})
+	}
+	text := strings.TrimSpace(body.Text)
+	if text == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(common.ErrorResponse{Error: "item text required"})
+	}
+	if utf8.RuneCountInString(text) > maxItemText {
+		return c.Status(fiber.StatusBadRequest).JSON(common.ErrorResponse{Error: "item text is too long"})
+	}
+	item, err := storeOf(c).AddItem(id, text)
+	if err != nil {
+		if errors.Is(err, store.ErrNoSuchTask) {
+			return c.Status(fiber.StatusBadRequest).JSON(common.ErrorResponse{Error: "no such task"})
+		}
+		logger.Get().Error("cannot add checklist item", zap.Error(err))
+		return c.Status(fiber.StatusInternalServerError).JSON(common.ErrorResponse{Error: "cannot add checklist item"})
+	}
+	return c.Status(fiber.StatusOK).JSON(common.SuccessResponse{Id: int(item)})
+}
+
+// ReorderChecklist puts the checklist of the task id in the order given by
+// the ids of all its items.
+func ReorderChecklist(c *fiber.Ctx) error {
+	id := c.Query("id")
+	if id == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(common.ErrorResponse{Error: "id required"})
+	}
+	var body common.ChecklistOrder
+	if err := c.BodyParser(&body); err != nil {
+		logger.Get().Info("cannot parse body", zap.Error(err))
+		return c.Status(fiber.StatusBadRequest).JSON(common.ErrorResponse{Error: "incorrect request"})
+	}
+	if err := storeOf(c).ReorderChecklist(id, body.Order); err != nil {
+		if errors.Is(err, store.ErrNoSuchTask) || errors.Is(err, store.ErrInvalidOrder) {
+			return c.Status(fiber.StatusBadRequest).JSON(common.ErrorResponse{Error: err.Error()})
+		}
+		logger.Get().Error("cannot reorder checklist", zap.Error(err))
+		return c.Status(fiber.StatusInternalServerError).JSON(common.ErrorResponse{Error: "cannot reorder checklist"})
+	}
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{})
+}
+
+// ToggleItem checks or unchecks the checklist item id.
+func ToggleItem(c *fiber.Ctx) error {
+	id := c.Query("item")
+	if id == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(common.ErrorResponse{Error: "item required"})
+	}
+	done, err := storeOf(c).ToggleItem(id)
+	if err != nil {
+		if errors.Is(err, store.ErrNoSuchItem) {
+			return c.Status(fiber.StatusBadRequest).JSON(common.ErrorResponse{Error: err.Error()})
+		}
+		logger.Get().Error("cannot toggle checklist item", zap.Error(err))
+		return c.Status(fiber.StatusInternalServerError).JSON(common.ErrorResponse{Error: "cannot toggle checklist item"})
+	}
+	return c.Status(fiber.StatusOK).JSON(common.ToggleItemResponse{Done: done})
+}
+
+func DeleteItem(c *fiber.Ctx) error {
+	id := c.Query("item")
+	if id == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(common.ErrorResponse{Error: "item required"})
+	}
+	if err := storeOf(c).DeleteItem(id); err != nil {
+		if errors.Is(err, store.ErrNoSuchItem) {
+			return c.Status(fiber.StatusBadRequest).JSON(common.ErrorResponse{Error: err.Error()})
+		}
+		logger.Get().Error("cannot delete checklist item", zap.Error(err))
+		return c.Status(fiber.StatusInternalServerError).JSON(common.ErrorResponse{Error: "cannot delete checklist item"})
+	}
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{})
+}
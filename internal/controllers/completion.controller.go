@@ -0,0 +1,57 @@
+package controllers
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"go.uber.org/zap"
+	"main/core/config"
+	"main/core/logger"
+	"main/internal/models/common"
+	"main/internal/models/completions"
+	"time"
+)
+
+func TaskHistory(c *fiber.Ctx) error {
+	id := c.Query("id")
+	if id == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(common.ErrorResponse{Error: "id required"})
+	}
+	result, err := storeOf(c).TaskHistory(id)
+	if err != nil {
+		logger.Get().Error("cannot get task history", zap.Error(err))
+		return c.Status(fiber.StatusInternalServerError).JSON(common.ErrorResponse{Error: "cannot get task history"})
+	}
+	if result == nil {
+		result = []completions.Completion{}
+	}
+	return c.Status(fiber.StatusOK).JSON(common.CompletionsResponse{Completions: result})
+}
+
+// GetCompletions lists completions made between the from and to dates
+// inclusive, taken in the configured time zone. Either bound may be omitted.
+func GetCompletions(c *fiber.Ctx) error {
+	var from, to time.Time
+	if c.Query("from") != "" {
+		parsed, err := time.ParseInLocation("20060102", c.Query("from"), config.Location())
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(common.ErrorResponse{Error: "invalid from date"})
+		}
+		from = parsed
+	}
+	if c.Query("to") != "" {
+		parsed, err := time.ParseInLocation("20060102", c.Query("to"), config.Location())
+		if err != nil || parsed.Before(from) {
+			return c.Status(fiber.StatusBadRequest).JSON(common.ErrorResponse{Error: "invalid to date"})
+		}
+		to = parsed.AddDate(0, 0, 1)
+	}
+
+	result, err := storeOf(c).Completions(from, to)
+	if err != nil {
+		logger.Get().Error("cannot get completions", zap.Error(err))
+		return c.Status(fiber.StatusInternalServerError).JSON(common.ErrorResponse{Error: "cannot get completions"})
+	}
+	if result == nil {
+		result = []completions.Completion{}
+	}
+	return c.Status(fiber.StatusOK).JSON(common.CompletionsResponse{Completions: result})
+}
@@ -0,0 +1,62 @@
+package controllers
+
+import (
+	"errors"
+	"github.com/gofiber/fiber/v2"
+	"go.uber.org/zap"
+	"main/core/database/store"
+	"main/core/logger"
+	"main/internal/models/common"
+	"main/internal/models/tasks"
+)
+
+// GetBlockers returns the tasks the task id is blocked by.
+func GetBlockers(c *fiber.Ctx) error {
+	id := c.Query("id")
+	if id == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(common.ErrorResponse{Error: "id required"})
+	}
+	blockers, err := storeOf(c).Blockers(id)
+	if err != nil {
+		if errors.Is(err, store.ErrNoSuchTask) {
+			return c.Status(fiber.StatusBadRequest).JSON(common.ErrorResponse{Error: "no such task"})
+		}
+		logger.Get().Error("cannot get blockers", zap.Error(err))
+		return c.Status(fiber.StatusInternalServerError).JSON(common.ErrorResponse{Error: "cannot get blockers"})
+	}
+	if blockers == nil {
+		blockers = []tasks.Task{}
+	}
+	return c.Status(fiber.StatusOK).JSON(common.BlockersResponse{Blockers: blockers})
+}
+
+// AddDependency makes the task id blocked by the task blocker.
+func AddDependency(c *fiber.Ctx) error {
+	id, blocker := c.Query("id"), c.Query("blocker")
+	if id == "" || blocker == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(common.ErrorResponse{Error: "id and blocker required"})
+	}
+	if err := storeOf(c).AddDependency(id, blocker); err != nil {
+		if errors.Is(err, store.ErrNoSuchTask) || errors.Is(err, store.ErrDependencyCycle) {
+			return c.Status(fiber.StatusBadRequest).JSON(common.ErrorResponse{Error: err.Error()})
+		}
+		logger.Get().Error("cannot add dependency", zap.Error(err))
+		return c.Status(fiber.StatusInternalServerError).JSON(common.ErrorResponse{Error: "cannot add dependency"})
+	}
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{})
+}
+
+func DeleteDependency(c *fiber.Ctx) error {
+	id, blocker := c.Query("id"), c.Query("blocker")
+	if id == "" || blocker == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(common.ErrorResponse{Error: "id and blocker required"})
+	}
+	if err := storeOf(c).DeleteDependency(id, blocker); err != nil {
+		if errors.Is(err, store.ErrNoSuchDependency) {
+			return c.Status(fiber.StatusBadRequest).JSON(common.ErrorResponse{Error: err.Error()})
+		}
+		logger.Get().Error("cannot delete dependency", zap.Error(err))
+		return c.Status(fiber.StatusInternalServerError).JSON(common.ErrorResponse{Error: "cannot delete dependency"})
+	}
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{})
+}
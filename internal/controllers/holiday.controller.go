@@ -0,0 +1,57 @@
+package controllers
+
+import (
+	"errors"
+	"github.com/gofiber/fiber/v2"
+	"go.uber.org/zap"
+	"main/core/database"
+	"main/core/database/store"
+	"main/core/logger"
+	"main/internal/models/common"
+	"main/internal/models/holidays"
+	"time"
+)
+
+func GetHolidays(c *fiber.Ctx) error {
+	result, err := database.Get().Holidays()
+	if err != nil {
+		logger.Get().Error("cannot get holidays", zap.Error(err))
+		return c.Status(fiber.StatusInternalServerError).JSON(common.ErrorResponse{Error: "cannot get holidays"})
+	}
+	if result == nil {
+		result = []holidays.Holiday{}
+	}
+	return c.Status(fiber.StatusOK).JSON(common.HolidaysResponse{Holidays: result})
+}
+
+func AddHoliday(c *fiber.Ctx) error {
+	var body holidays.Holiday
+	if err := c.BodyParser(&body); err != nil {
+		logger.Get().Info("cannot parse body", zap.Error(err))
+		return c.Status(fiber.StatusBadRequest).JSON(common.ErrorResponse{Error: "incorrect request"})
+	}
+	if _, err := time.Parse("20060102", body.Date); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(common.ErrorResponse{Error: "дата представлена в формате, отличном от 20060102"})
+	}
+	if err := database.Get().AddHoliday(body); err != nil {
+		logger.Get().Error("cannot add holiday", zap.Error(err))
+		return c.Status(fiber.StatusInternalServerError).JSON(common.ErrorResponse{Error: "cannot add holiday"})
+	}
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{})
+}
+
+func DeleteHoliday(c *fiber.Ctx) error {
+	date := c.Query("date")
+	if date == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(common.ErrorResponse{Error: "date required"})
+	}
+	if err := database.Get().DeleteHoliday(date); err != nil {
+		if errors.Is(err, store.ErrNoSuchHoliday) {
+			logger.Get().Info("no such holiday", zap.Error(err))
+			return c.Status(fiber.StatusBadRequest).JSON(common.ErrorResponse{Error: "no such holiday"})
+		}
+		logger.Get().Error("cannot delete holiday", zap.Error(err))
+		return c.Status(fiber.StatusInternalServerError).JSON(common.ErrorResponse{Error: "cannot delete holiday"})
+	}
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{})
+}
@@ -0,0 +1,99 @@
+package controllers
+
+import (
+	"errors"
+	"github.com/gofiber/fiber/v2"
+	"go.uber.org/zap"
+	"main/core/database/store"
+	"main/core/logger"
+	"main/internal/models/common"
+	"main/internal/models/lists"
+	"strings"
+	"unicode/utf8"
+)
+
+const maxListName = 64
+
+// checkListName trims the name of a list and checks that it fits the column.
+func checkListName(list *lists.List) error {
+	list.Name = strings.TrimSpace(list.Name)
+	if list.Name == "" {
+		return errors.New("list name required")
+	}
+	if utf8.RuneCountInString(list.Name) > maxListName {
+		return errors.New("list name is too long")
+	}
+	return nil
+}
+
+// GetLists returns the lists of the user with the number of tasks in each.
+func GetLists(c *fiber.Ctx) error {
+	result, err := storeOf(c).Lists()
+	if err != nil {
+		logger.Get().Error("cannot get lists", zap.Error(err))
+		return c.Status(fiber.StatusInternalServerError).JSON(common.ErrorResponse{Error: "cannot get lists"})
+	}
+	if result == nil {
+		result = []lists.List{}
+	}
+	return c.Status(fiber.StatusOK).JSON(common.ListsResponse{Lists: result})
+}
+
+func AddList(c *fiber.Ctx) error {
+	var body lists.List
+	if err := c.BodyParser(&body); err != nil {
+		logger.Get().Info("cannot parse body", zap.Error(err))
+		return c.Status(fiber.StatusBadRequest).JSON(common.ErrorResponse{Error: "incorrect request"})
+	}
+	if err := checkListName(&body); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(common.ErrorResponse{Error: err.Error()})
+	}
+	id, err := storeOf(c).AddList(body)
+	if err != nil {
+		if errors.Is(err, store.ErrListExists) {
+			return c.Status(fiber.StatusBadRequest).JSON(common.ErrorResponse{Error: "list already exists"})
+		}
+		logger.Get().Error("cannot add list", zap.Error(err))
+		return c.Status(fiber.StatusInternalServerError).JSON(common.ErrorResponse{Error: "cannot add list"})
+	}
+	return c.Status(fiber.StatusOK).JSON(common.SuccessResponse{Id: int(id)})
+}
+
+// UpdateList renames a list.
+func UpdateList(c *fiber.Ctx) error {
+	var body lists.List
+	if err := c.BodyParser(&body); err != nil {
+		logger.Get().Info("cannot parse body", zap.Error(err))
+		return c.Status(fiber.StatusBadRequest).JSON(common.ErrorResponse{Error: "incorrect request"})
+	}
+	if body.ID == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(common.ErrorResponse{Error: "id required"})
+	}
+	if err := checkListName(&body); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(common.ErrorResponse{Error: err.Error()})
+	}
+	if err := storeOf(c).UpdateList(body); err != nil {
+		if errors.Is(err, store.ErrNoSuchList) || errors.Is(err, store.ErrListExists) {
+			return c.Status(fiber.StatusBadRequest).JSON(common.ErrorResponse{Error: err.Error()})
+		}
+		logger.Get().Error("cannot update list", zap.Error(err))
+		return c.Status(fiber.StatusInternalServerError).JSON(common.ErrorResponse{Error: "cannot update list"})
+	}
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{})
+}
+
+// DeleteList deletes a list. Its tasks are kept out of any list.
+func DeleteList(c *fiber.Ctx) error {
+	id := c.Query("id")
+	if id == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(common.ErrorResponse{Error: "id required"})
+	}
+	if err := storeOf(c).DeleteList(id); err != nil {
+		if errors.Is(err, store.ErrNoSuchList) {
+			return c.Status(fiber.StatusBadRequest).JSON(common.ErrorResponse{Error: "no such list"})
+		}
+		logger.Get().Error("cannot delete list", zap.Error(err))
+		return c.Status(fiber.StatusInternalServerError).JSON(common.ErrorResponse{Error: "cannot delete list"})
+	}
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{})
+}
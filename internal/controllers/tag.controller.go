@@ -0,0 +1,103 @@
+package controllers
+
+import (
+	"errors"
+	"github.com/gofiber/fiber/v2"
+	"go.uber.org/zap"
+	"main/core/database/store"
+	"main/core/logger"
+	"main/internal/models/common"
+	"main/internal/models/tags"
+)
+
+// GetTags returns the tags of the user with the number of tasks marked with
+// each.
+func GetTags(c *fiber.Ctx) error {
+	result, err := storeOf(c).Tags()
+	if err != nil {
+		logger.Get().Error("cannot get tags", zap.Error(err))
+		return c.Status(fiber.StatusInternalServerError).JSON(common.ErrorResponse{Error: "cannot get tags"})
+	}
+	if result == nil {
+		result = []tags.Tag{}
+	}
+	return c.Status(fiber.StatusOK).JSON(common.TagsResponse{Tags: result})
+}
+
+func AddTag(c *fiber.Ctx) error {
+	var body tags.Tag
+	if err := c.BodyParser(&body); err != nil {
+		logger.Get().Info("cannot parse body", zap.Error(err))
+		return c.Status(fiber.StatusBadRequest).JSON(common.ErrorResponse{Error: "incorrect request"})
+	}
+	name, err := store.NormalizeTag(body.Name)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(common.ErrorResponse{Error: err.Error()})
+	}
+	id, err := storeOf(c).AddTag(tags.Tag{Name: name})
+	if err != nil {
+		if errors.Is(err, store.ErrTagExists) {
+			return c.Status(fiber.StatusBadRequest).JSON(common.ErrorResponse{Error: "tag already exists"})
+		}
+		logger.Get().Error("cannot add tag", zap.Error(err))
+		return c.Status(fiber.StatusInternalServerError).JSON(common.ErrorResponse{Error: "cannot add tag"})
+	}
+	return c.Status(fiber.StatusOK).JSON(common.SuccessResponse{Id: int(id)})
+}
+
+// UpdateTag renames a tag. A tag cannot be renamed to another existing tag,
+// which is what MergeTags is for.
+func UpdateTag(c *fiber.Ctx) error {
+	var body tags.Tag
+	if err := c.BodyParser(&body); err != nil {
+		logger.Get().Info("cannot parse body", zap.Error(err))
+		return c.Status(fiber.StatusBadRequest).JSON(common.ErrorResponse{Error: "incorrect request"})
+	}
+	if body.ID == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(common.ErrorResponse{Error: "id required"})
+	}
+	name, err := store.NormalizeTag(body.Name)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(common.ErrorResponse{Error: err.Error()})
+	}
+	if err = storeOf(c).UpdateTag(tags.Tag{ID: body.ID, Name: name}); err != nil {
+		if errors.Is(err, store.ErrNoSuchTag) || errors.Is(err, store.ErrTagExists) {
+			return c.Status(fiber.StatusBadRequest).JSON(common.ErrorResponse{Error: err.Error()})
+		}
+		logger.Get().Error("cannot update tag", zap.Error(err))
+		return c.Status(fiber.StatusInternalServerError).JSON(common.ErrorResponse{Error: "cannot update tag"})
+	}
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{})
+}
+
+// MergeTags moves the tasks of tag from to tag into and deletes from.
+func MergeTags(c *fiber.Ctx) error {
+	from, into := c.Query("from"), c.Query("into")
+	if from == "" || into == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(common.ErrorResponse{Error: "from and into required"})
+	}
+	if err := storeOf(c).MergeTags(from, into); err != nil {
+		if errors.Is(err, store.ErrNoSuchTag) {
+			return c.Status(fiber.StatusBadRequest).JSON(common.ErrorResponse{Error: "no such tag"})
+		}
+		logger.Get().Error("cannot merge tags", zap.Error(err))
+		return c.Status(fiber.StatusInternalServerError).JSON(common.ErrorResponse{Error: "cannot merge tags"})
+	}
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{})
+}
+
+// DeleteTag deletes a tag, removing it from its tasks.
+func DeleteTag(c *fiber.Ctx) error {
+	id := c.Query("id")
+	if id == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(common.ErrorResponse{Error: "id required"})
+	}
+	if err := storeOf(c).DeleteTag(id); err != nil {
+		if errors.Is(err, store.ErrNoSuchTag) {
+			return c.Status(fiber.StatusBadRequest).JSON(common.ErrorResponse{Error: "no such tag"})
+		}
+		logger.Get().Error("cannot delete tag", zap.Error(err))
+		return c.Status(fiber.StatusInternalServerError).JSON(common.ErrorResponse{Error: "cannot delete tag"})
+	}
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{})
+}
@@ -2,9 +2,11 @@ package controllers
 
 import (
 	"errors"
+	"fmt"
 	"github.com/gofiber/fiber/v2"
 	"go.uber.org/zap"
-	"main/core/database/sqlite"
+	"main/core/config"
+	"main/core/database/store"
 	"main/core/logger"
 	"main/internal/models/common"
 	"main/internal/models/tasks"
@@ -13,6 +15,11 @@ import (
 	"time"
 )
 
+const (
+	defaultOccurrences = 10
+	maxOccurrences     = 1000
+)
+
 func AddTask(c *fiber.Ctx) error {
 	var body common.AddTask
 	if err := c.BodyParser(&body); err != nil {
@@ -23,12 +30,25 @@ func AddTask(c *fiber.Ctx) error {
 		logger.Get().Info("internal check failed", zap.Error(err))
 		return c.Status(fiber.StatusBadRequest).JSON(common.ErrorResponse{Error: err.Error()})
 	}
-	id, err := sqlite.Get().AddTaskDB(tasks.Task{
-		Date:    body.Date,
-		Title:   body.Title,
-		Comment: body.Comment,
-		Repeat:  body.Repeat,
+	names, err := store.NormalizeTags(body.Tags)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(common.ErrorResponse{Error: err.Error()})
+	}
+	id, err := storeOf(c).AddTaskDB(tasks.Task{
+		Date:       body.Date,
+		Time:       body.Time,
+		Title:      body.Title,
+		Comment:    body.Comment,
+		Repeat:     body.Repeat,
+		Shift:      body.Shift,
+		ListID:     body.ListID,
+		Priority:   body.Priority,
+		Tags:       names,
+		Occurrence: body.Occurrence,
 	})
+	if errors.Is(err, store.ErrNoSuchList) {
+		return c.Status(fiber.StatusBadRequest).JSON(common.ErrorResponse{Error: "no such list"})
+	}
 	if err != nil {
 		logger.Get().Info("cannot add task", zap.Error(err))
 		return c.Status(fiber.StatusBadRequest).JSON(common.ErrorResponse{Error: "cannot add task"})
@@ -36,36 +56,169 @@ func AddTask(c *fiber.Ctx) error {
 	return c.Status(fiber.StatusOK).JSON(common.SuccessResponse{Id: int(id)})
 }
 
+// GetTasks lists tasks a page at a time, or searches them if search is set.
+// The list can be filtered by from and to dates, by recurring, by list, by tag
+// and by priority, and sorted by date, title or id in either order.
 func GetTasks(c *fiber.Ctx) error {
-	var offset int
-	if c.Query("offset") != "" && c.Query("offset") != "" {
-		if temp, err := strconv.Atoi(c.Query("offset")); err == nil {
-			offset = temp
-		}
+	page, err := taskPage(c)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(common.ErrorResponse{Error: err.Error()})
 	}
 	if c.Query("search") != "" {
+		for _, param := range []string{"from", "to", "recurring", "list", "tag", "priority", "sort", "order"} {
+			if c.Query(param) != "" {
+				return c.Status(fiber.StatusBadRequest).JSON(common.ErrorResponse{
+					Error: param + " cannot be used with search, use search filters instead"})
+			}
+		}
 		if parsedDate, err := time.Parse("02.1.2006", c.Query("search")); err == nil {
-			resultTasks, err := sqlite.Get().TasksByDate(parsedDate.Format("20060102"))
+			date := parsedDate.Format("20060102")
+			result, err := storeOf(c).Tasks(store.TaskQuery{From: date, To: date, Page: page})
 			if err != nil {
+				if errors.Is(err, store.ErrInvalidCursor) {
+					return c.Status(fiber.StatusBadRequest).JSON(common.ErrorResponse{Error: err.Error()})
+				}
 				logger.Get().Info("cannot get tasks", zap.Error(err))
 				return c.Status(fiber.StatusBadRequest).JSON(common.ErrorResponse{Error: "cannot get tasks"})
 			}
-			return c.Status(fiber.StatusOK).JSON(common.SuccessResponse{Tasks: resultTasks})
+			return taskList(c, result)
 		}
-		resultTasks, err := sqlite.Get().SearchTasks(c.Query("search"), offset)
+		filter, err := store.ParseFilter(c.Query("search"))
 		if err != nil {
-			return c.Status(fiber.StatusOK).JSON(common.SuccessResponse{Tasks: resultTasks})
+			logger.Get().Info("cannot parse search", zap.Error(err))
+			return c.Status(fiber.StatusBadRequest).JSON(common.ErrorResponse{Error: err.Error()})
 		}
+		result, err := storeOf(c).SearchTasks(filter, page)
+		if err != nil {
+			if errors.Is(err, store.ErrInvalidCursor) {
+				return c.Status(fiber.StatusBadRequest).JSON(common.ErrorResponse{Error: err.Error()})
+			}
+			logger.Get().Info("cannot search tasks", zap.Error(err))
+			return c.Status(fiber.StatusInternalServerError).JSON(common.ErrorResponse{Error: "cannot search tasks"})
+		}
+		return taskList(c, result)
+	}
+
+	query, err := taskQuery(c)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(common.ErrorResponse{Error: err.Error()})
 	}
-	resultTasks, err := sqlite.Get().Tasks(offset)
+	query.Page = page
+	result, err := storeOf(c).Tasks(query)
 	if err != nil {
+		if errors.Is(err, store.ErrInvalidCursor) {
+			return c.Status(fiber.StatusBadRequest).JSON(common.ErrorResponse{Error: err.Error()})
+		}
 		logger.Get().Info("cannot get resultTasks", zap.Error(err))
 		return c.Status(fiber.StatusInternalServerError).JSON(common.ErrorResponse{Error: "cannot get resultTasks"})
 	}
-	if resultTasks == nil {
-		resultTasks = []tasks.Task{}
+	return taskList(c, result)
+}
+
+// taskList writes a page of tasks. Clients paging with a cursor also get the
+// cursor of the next page and the number of tasks on all pages.
+func taskList(c *fiber.Ctx, page store.TaskPage) error {
+	if page.Tasks == nil {
+		page.Tasks = []tasks.Task{}
+	}
+	if !c.Context().QueryArgs().Has("cursor") {
+		return c.Status(fiber.StatusOK).JSON(fiber.Map{"tasks": page.Tasks})
+	}
+	response := common.SuccessResponse{Tasks: &page.Tasks, Total: &page.Total}
+	if page.Next != nil {
+		response.NextCursor = page.Next.String()
+	}
+	return c.Status(fiber.StatusOK).JSON(response)
+}
+
+// taskPage reads offset and limit, or cursor, which is empty for the first
+// page. A limit above the configured maximum is lowered to it.
+func taskPage(c *fiber.Ctx) (store.Page, error) {
+	var page store.Page
+	if c.Context().QueryArgs().Has("cursor") {
+		if c.Query("offset") != "" {
+			return store.Page{}, errors.New("offset cannot be used with cursor")
+		}
+		if c.Query("cursor") != "" {
+			after, err := store.ParseCursor(c.Query("cursor"))
+			if err != nil {
+				return store.Page{}, err
+			}
+			page.After = after
+		}
 	}
-	return c.Status(fiber.StatusOK).JSON(fiber.Map{"tasks": resultTasks})
+	if c.Query("offset") != "" {
+		if temp, err := strconv.Atoi(c.Query("offset")); err == nil {
+			page.Offset = temp
+		}
+	}
+	if c.Query("limit") != "" {
+		limit, err := strconv.Atoi(c.Query("limit"))
+		if err != nil || limit < 1 {
+			return store.Page{}, errors.New("invalid limit")
+		}
+		page.Limit = min(limit, config.Get().Tasks.MaxPageSize)
+	}
+	return page, nil
+}
+
+// taskQuery reads the filter and sort parameters of the task list.
+func taskQuery(c *fiber.Ctx) (store.TaskQuery, error) {
+	var query store.TaskQuery
+	for _, param := range []string{"from", "to"} {
+		if c.Query(param) == "" {
+			continue
+		}
+		if _, err := time.Parse("20060102", c.Query(param)); err != nil {
+			return store.TaskQuery{}, fmt.Errorf("invalid %s date", param)
+		}
+	}
+	query.From, query.To = c.Query("from"), c.Query("to")
+
+	if c.Query("recurring") != "" {
+		recurring, err := strconv.ParseBool(c.Query("recurring"))
+		if err != nil {
+			return store.TaskQuery{}, errors.New("recurring must be true or false")
+		}
+		query.Recurring = &recurring
+	}
+
+	if c.Query("list") != "" {
+		list, err := strconv.ParseInt(c.Query("list"), 10, 64)
+		if err != nil || list < 1 {
+			return store.TaskQuery{}, errors.New("invalid list")
+		}
+		query.List = list
+	}
+	if c.Query("tag") != "" {
+		tag, err := store.NormalizeTag(c.Query("tag"))
+		if err != nil {
+			return store.TaskQuery{}, err
+		}
+		query.Tag = tag
+	}
+	if c.Query("priority") != "" {
+		priority, err := store.ParsePriority(c.Query("priority"))
+		if err != nil {
+			return store.TaskQuery{}, err
+		}
+		query.Priority = priority
+	}
+
+	switch c.Query("sort") {
+	case "", store.SortDate, store.SortTitle, store.SortID:
+		query.Sort = c.Query("sort")
+	default:
+		return store.TaskQuery{}, errors.New("sort must be date, title or id")
+	}
+	switch c.Query("order") {
+	case "", "asc":
+	case "desc":
+		query.Desc = true
+	default:
+		return store.TaskQuery{}, errors.New("order must be asc or desc")
+	}
+	return query, nil
 }
 
 func GetTask(c *fiber.Ctx) error {
@@ -73,9 +226,9 @@ func GetTask(c *fiber.Ctx) error {
 	if id == "" {
 		return c.Status(fiber.StatusBadRequest).JSON(common.ErrorResponse{Error: "id required"})
 	}
-	task, err := sqlite.Get().FindTask(id)
+	task, err := storeOf(c).FindTask(id)
 	if err != nil {
-		if errors.Is(err, sqlite.ErrNoSuchTask) {
+		if errors.Is(err, store.ErrNoSuchTask) {
 			logger.Get().Info("cannot find task", zap.Error(err))
 			return c.Status(fiber.StatusBadRequest).JSON(common.ErrorResponse{Error: "cannot find task"})
 		}
@@ -101,20 +254,28 @@ func UpdateTask(c *fiber.Ctx) error {
 		return c.Status(fiber.StatusBadRequest).JSON(common.ErrorResponse{Error: "id required"})
 	}
 	validate := common.AddTask{
-		Date:    body.Date,
-		Title:   body.Title,
-		Comment: body.Comment,
-		Repeat:  body.Repeat,
+		Date:     body.Date,
+		Time:     body.Time,
+		Title:    body.Title,
+		Comment:  body.Comment,
+		Repeat:   body.Repeat,
+		Shift:    body.Shift,
+		Priority: body.Priority,
 	}
 	if err := validate.CheckTask(); err != nil {
 		logger.Get().Info("internal check failed", zap.Error(err))
 		return c.Status(fiber.StatusBadRequest).JSON(common.ErrorResponse{Error: err.Error()})
 	}
-	if _, err := sqlite.Get().FindTask(body.ID); err != nil {
+	names, err := store.NormalizeTags(body.Tags)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(common.ErrorResponse{Error: err.Error()})
+	}
+	body.Tags = names
+	if _, err := storeOf(c).FindTask(body.ID); err != nil {
 		return c.Status(fiber.StatusNotFound).JSON(common.ErrorResponse{Error: "cannot find task"})
 	}
-	if err := sqlite.Get().UpdateTask(body); err != nil {
-		if errors.Is(err, sqlite.ErrNoSuchTask) {
+	if err := storeOf(c).UpdateTask(body); err != nil {
+		if errors.Is(err, store.ErrNoSuchTask) {
 			logger.Get().Info("no such task", zap.Error(err))
 			return c.Status(fiber.StatusBadRequest).JSON(common.ErrorResponse{Error: "no such task"})
 		}
@@ -129,11 +290,21 @@ func DoneTask(c *fiber.Ctx) error {
 	if id == "" {
 		return c.Status(fiber.StatusBadRequest).JSON(common.ErrorResponse{Error: "invalid id"})
 	}
-	if err := sqlite.Get().DoneTask(id); err != nil {
-		if errors.Is(err, sqlite.ErrNoSuchTask) {
+	var body common.DoneTask
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&body); err != nil {
+			logger.Get().Info("cannot parse body", zap.Error(err))
+			return c.Status(fiber.StatusBadRequest).JSON(common.ErrorResponse{Error: "incorrect request"})
+		}
+	}
+	if err := storeOf(c).DoneTask(id, body.Note); err != nil {
+		if errors.Is(err, store.ErrNoSuchTask) {
 			logger.Get().Info("no such task", zap.Error(err))
 			return c.Status(fiber.StatusBadRequest).JSON(common.ErrorResponse{Error: "no such task"})
 		}
+		if errors.Is(err, store.ErrTaskBlocked) {
+			return c.Status(fiber.StatusBadRequest).JSON(common.ErrorResponse{Error: err.Error()})
+		}
 		logger.Get().Error("cannot done task", zap.Error(err))
 		return c.Status(fiber.StatusInternalServerError).JSON(common.ErrorResponse{Error: "cannot done task"})
 	}
@@ -145,8 +316,8 @@ func DeleteTask(c *fiber.Ctx) error {
 	if id == "" {
 		return c.Status(fiber.StatusBadRequest).JSON(common.ErrorResponse{Error: "invalid id"})
 	}
-	if err := sqlite.Get().DeleteTask(id); err != nil {
-		if errors.Is(err, sqlite.ErrNoSuchTask) {
+	if err := storeOf(c).DeleteTask(id); err != nil {
+		if errors.Is(err, store.ErrNoSuchTask) {
 			logger.Get().Info("no such task", zap.Error(err))
 			return c.Status(fiber.StatusBadRequest).JSON(common.ErrorResponse{Error: "no such task"})
 		}
@@ -156,14 +327,102 @@ func DeleteTask(c *fiber.Ctx) error {
 	return c.Status(fiber.StatusOK).JSON(fiber.Map{})
 }
 
+// MoveTask moves a task to the list given by list, or out of any list if it
+// is empty.
+func MoveTask(c *fiber.Ctx) error {
+	id := c.Query("id")
+	if id == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(common.ErrorResponse{Error: "invalid id"})
+	}
+	var list int64
+	if c.Query("list") != "" {
+		var err error
+		if list, err = strconv.ParseInt(c.Query("list"), 10, 64); err != nil || list < 1 {
+			return c.Status(fiber.StatusBadRequest).JSON(common.ErrorResponse{Error: "invalid list"})
+		}
+	}
+	if err := storeOf(c).MoveTask(id, list); err != nil {
+		if errors.Is(err, store.ErrNoSuchTask) || errors.Is(err, store.ErrNoSuchList) {
+			logger.Get().Info("cannot move task", zap.Error(err))
+			return c.Status(fiber.StatusBadRequest).JSON(common.ErrorResponse{Error: err.Error()})
+		}
+		logger.Get().Error("cannot move task", zap.Error(err))
+		return c.Status(fiber.StatusInternalServerError).JSON(common.ErrorResponse{Error: "cannot move task"})
+	}
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{})
+}
+
 func NextDate(c *fiber.Ctx) error {
 	now, err := time.Parse("20060102", c.Query("now"))
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(common.ErrorResponse{Error: err.Error()})
 	}
-	next, err := pkg.NextDate(now, c.Query("date"), c.Query("repeat"))
+	shift, err := pkg.ParseShift(c.Query("shift"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(common.ErrorResponse{Error: err.Error()})
+	}
+	var next string
+	if shift == pkg.ShiftNone {
+		next, err = pkg.NextDate(now, c.Query("date"), c.Query("repeat"))
+	} else {
+		cal, calErr := storeOf(c).Calendar()
+		if calErr != nil {
+			logger.Get().Error("cannot load holidays", zap.Error(calErr))
+			return c.Status(fiber.StatusInternalServerError).JSON(common.ErrorResponse{Error: "cannot load holidays"})
+		}
+		next, err = cal.NextDate(now, c.Query("date"), c.Query("repeat"), shift)
+	}
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(common.ErrorResponse{Error: err.Error()})
 	}
 	return c.SendString(next)
 }
+
+func Occurrences(c *fiber.Ctx) error {
+	from := pkg.Today(config.Location())
+	if c.Query("from") != "" {
+		parsed, err := time.Parse("20060102", c.Query("from"))
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(common.ErrorResponse{Error: "invalid from date"})
+		}
+		from = parsed
+	}
+	var to time.Time
+	if c.Query("to") != "" {
+		parsed, err := time.Parse("20060102", c.Query("to"))
+		if err != nil || parsed.Before(from) {
+			return c.Status(fiber.StatusBadRequest).JSON(common.ErrorResponse{Error: "invalid to date"})
+		}
+		to = parsed
+	}
+	limit := defaultOccurrences
+	if c.Query("limit") != "" {
+		parsed, err := strconv.Atoi(c.Query("limit"))
+		if err != nil || parsed < 1 || parsed > maxOccurrences {
+			return c.Status(fiber.StatusBadRequest).JSON(common.ErrorResponse{Error: "invalid limit"})
+		}
+		limit = parsed
+	}
+
+	shift, err := pkg.ParseShift(c.Query("shift"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(common.ErrorResponse{Error: err.Error()})
+	}
+
+	var cal *pkg.Calendar
+	if shift != pkg.ShiftNone {
+		if cal, err = storeOf(c).Calendar(); err != nil {
+			logger.Get().Error("cannot load holidays", zap.Error(err))
+			return c.Status(fiber.StatusInternalServerError).JSON(common.ErrorResponse{Error: "cannot load holidays"})
+		}
+	}
+	dates, err := cal.ShiftedOccurrences(c.Query("date"), c.Query("repeat"), from, to, limit, shift)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(common.ErrorResponse{Error: err.Error()})
+	}
+	result := common.OccurrencesResponse{Dates: make([]string, len(dates))}
+	for i, date := range dates {
+		result.Dates[i] = date.Format("20060102")
+	}
+	return c.Status(fiber.StatusOK).JSON(result)
+}
@@ -0,0 +1,65 @@
+package controllers
+
+import (
+	"errors"
+	"github.com/gofiber/fiber/v2"
+	"go.uber.org/zap"
+	"main/core/database/store"
+	"main/core/files"
+	"main/core/logger"
+	"main/internal/models/common"
+	"main/internal/models/tasks"
+	"time"
+)
+
+func GetTrash(c *fiber.Ctx) error {
+	result, err := storeOf(c).Trash()
+	if err != nil {
+		logger.Get().Error("cannot get trash", zap.Error(err))
+		return c.Status(fiber.StatusInternalServerError).JSON(common.ErrorResponse{Error: "cannot get trash"})
+	}
+	if result == nil {
+		result = []tasks.Task{}
+	}
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{"tasks": result})
+}
+
+func RestoreTask(c *fiber.Ctx) error {
+	id := c.Query("id")
+	if id == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(common.ErrorResponse{Error: "invalid id"})
+	}
+	if err := storeOf(c).RestoreTask(id); err != nil {
+		if errors.Is(err, store.ErrNoSuchTask) {
+			logger.Get().Info("no such task in trash", zap.Error(err))
+			return c.Status(fiber.StatusBadRequest).JSON(common.ErrorResponse{Error: "no such task in trash"})
+		}
+		logger.Get().Error("cannot restore task", zap.Error(err))
+		return c.Status(fiber.StatusInternalServerError).JSON(common.ErrorResponse{Error: "cannot restore task"})
+	}
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{})
+}
+
+// PurgeTrash permanently deletes the task given by id, or every task in the
+// trash if no id is given, removing the files attached to them.
+func PurgeTrash(c *fiber.Ctx) error {
+	id := c.Query("id")
+	if id == "" {
+		if _, err := storeOf(c).PurgeTrash(time.Time{}); err != nil {
+			logger.Get().Error("cannot purge trash", zap.Error(err))
+			return c.Status(fiber.StatusInternalServerError).JSON(common.ErrorResponse{Error: "cannot purge trash"})
+		}
+		files.Purge(storeOf(c))
+		return c.Status(fiber.StatusOK).JSON(fiber.Map{})
+	}
+	if err := storeOf(c).PurgeTask(id); err != nil {
+		if errors.Is(err, store.ErrNoSuchTask) {
+			logger.Get().Info("no such task in trash", zap.Error(err))
+			return c.Status(fiber.StatusBadRequest).JSON(common.ErrorResponse{Error: "no such task in trash"})
+		}
+		logger.Get().Error("cannot purge task", zap.Error(err))
+		return c.Status(fiber.StatusInternalServerError).JSON(common.ErrorResponse{Error: "cannot purge task"})
+	}
+	files.Purge(storeOf(c))
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{})
+}
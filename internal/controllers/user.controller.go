@@ -0,0 +1,83 @@
+package controllers
+
+import (
+	"errors"
+	"github.com/gofiber/fiber/v2"
+	"go.uber.org/zap"
+	"golang.org/x/crypto/bcrypt"
+	"main/core/database"
+	"main/core/database/store"
+	"main/core/files"
+	"main/core/logger"
+	"main/internal/models/common"
+	"main/internal/models/users"
+	"strconv"
+)
+
+const maxLoginLength = 64
+
+func parseUserID(id string) (int64, error) {
+	return strconv.ParseInt(id, 10, 64)
+}
+
+func GetUsers(c *fiber.Ctx) error {
+	result, err := database.Get().Users()
+	if err != nil {
+		logger.Get().Error("cannot get users", zap.Error(err))
+		return c.Status(fiber.StatusInternalServerError).JSON(common.ErrorResponse{Error: "cannot get users"})
+	}
+	if result == nil {
+		result = []users.User{}
+	}
+	return c.Status(fiber.StatusOK).JSON(common.UsersResponse{Users: result})
+}
+
+func AddUser(c *fiber.Ctx) error {
+	var body struct {
+		Login    string `json:"login"`
+		Password string `json:"password"`
+	}
+	if err := c.BodyParser(&body); err != nil {
+		logger.Get().Info("cannot parse body", zap.Error(err))
+		return c.Status(fiber.StatusBadRequest).JSON(common.ErrorResponse{Error: "incorrect request"})
+	}
+	if body.Login == "" || len(body.Login) > maxLoginLength {
+		return c.Status(fiber.StatusBadRequest).JSON(common.ErrorResponse{Error: "login must be 1 to 64 bytes long"})
+	}
+	if body.Password == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(common.ErrorResponse{Error: "password required"})
+	}
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(body.Password), bcrypt.DefaultCost)
+	if err != nil {
+		// bcrypt only fails on passwords over 72 bytes.
+		return c.Status(fiber.StatusBadRequest).JSON(common.ErrorResponse{Error: err.Error()})
+	}
+	id, err := database.Get().AddUser(users.User{Login: body.Login, PasswordHash: string(hash)})
+	if err != nil {
+		if errors.Is(err, store.ErrUserExists) {
+			return c.Status(fiber.StatusBadRequest).JSON(common.ErrorResponse{Error: "user already exists"})
+		}
+		logger.Get().Error("cannot add user", zap.Error(err))
+		return c.Status(fiber.StatusInternalServerError).JSON(common.ErrorResponse{Error: "cannot add user"})
+	}
+	return c.Status(fiber.StatusOK).JSON(common.SuccessResponse{Id: int(id)})
+}
+
+// DeleteUser deletes an account together with its tasks and their attached
+// files.
+func DeleteUser(c *fiber.Ctx) error {
+	id, err := parseUserID(c.Query("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(common.ErrorResponse{Error: "incorrect id"})
+	}
+	if err = database.Get().DeleteUser(id); err != nil {
+		if errors.Is(err, store.ErrNoSuchUser) {
+			return c.Status(fiber.StatusBadRequest).JSON(common.ErrorResponse{Error: "no such user"})
+		}
+		logger.Get().Error("cannot delete user", zap.Error(err))
+		return c.Status(fiber.StatusInternalServerError).JSON(common.ErrorResponse{Error: "cannot delete user"})
+	}
+	files.Purge(database.Get())
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{})
+}
@@ -0,0 +1,13 @@
+package attachments
+
+// Attachment is a file attached to a task. The file itself is kept on disk
+// under the name File; the store keeps the rest.
+type Attachment struct {
+	ID          string `db:"id" json:"id"`
+	TaskID      int64  `db:"task_id" json:"-"`
+	Name        string `db:"name" json:"name"`
+	Size        int64  `db:"size" json:"size"`
+	ContentType string `db:"content_type" json:"content_type"`
+	File        string `db:"file" json:"-"`
+	CreatedAt   string `db:"created_at" json:"created_at"`
+}
@@ -0,0 +1,10 @@
+package checklists
+
+// Item is a step of a task's checklist. Items are ordered by Position.
+type Item struct {
+	ID       string `db:"id" json:"id"`
+	TaskID   int64  `db:"task_id" json:"-"`
+	Position int    `db:"position" json:"-"`
+	Text     string `db:"text" json:"text"`
+	Done     bool   `db:"done" json:"done"`
+}
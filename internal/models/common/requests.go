@@ -1,8 +1,53 @@
 package common
 
+import (
+	"encoding/json"
+
+	"main/internal/models/tasks"
+)
+
 type AddTask struct {
-	Date    string `json:"date,omitempty" binding:"required"`
-	Title   string `json:"title" binding:"required"`
-	Comment string `json:"comment,omitempty"`
-	Repeat  string `json:"repeat,omitempty"`
+	Date     string   `json:"date,omitempty" binding:"required"`
+	Time     string   `json:"time,omitempty"`
+	Title    string   `json:"title" binding:"required"`
+	Comment  string   `json:"comment,omitempty"`
+	Repeat   string   `json:"repeat,omitempty"`
+	Shift    string   `json:"shift,omitempty"`
+	ListID   int64    `json:"list_id,string,omitempty"`
+	Priority int      `json:"priority,string,omitempty"`
+	Tags     []string `json:"tags,omitempty"`
+	// Occurrence is set by CheckTask to which occurrence of the series Date
+	// is, counting occurrences skipped when a past date is rolled forward.
+	Occurrence int `json:"-"`
+}
+
+// UnmarshalJSON reads a task accepting its list id and priority either as
+// numbers or as strings.
+func (t *AddTask) UnmarshalJSON(data []byte) error {
+	type addTask AddTask
+	v := struct {
+		addTask
+		ListID   tasks.Number `json:"list_id"`
+		Priority tasks.Number `json:"priority"`
+	}{addTask: addTask(*t), ListID: tasks.Number(t.ListID), Priority: tasks.Number(t.Priority)}
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+	*t = AddTask(v.addTask)
+	t.ListID = int64(v.ListID)
+	t.Priority = int(v.Priority)
+	return nil
+}
+
+type DoneTask struct {
+	Note string `json:"note,omitempty"`
+}
+
+type ChecklistItem struct {
+	Text string `json:"text"`
+}
+
+type ChecklistOrder struct {
+	// Order is the ids of all items of the checklist in their new order.
+	Order []string `json:"order"`
 }
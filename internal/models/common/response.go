@@ -1,12 +1,75 @@
 package common
 
-import "main/internal/models/tasks"
+import (
+	"main/internal/models/attachments"
+	"main/internal/models/checklists"
+	"main/internal/models/completions"
+	"main/internal/models/holidays"
+	"main/internal/models/lists"
+	"main/internal/models/tags"
+	"main/internal/models/tasks"
+	"main/internal/models/users"
+)
 
 type ErrorResponse struct {
 	Error string `json:"error"`
 }
 
+// SuccessResponse carries the id of a new object, or a page of tasks. Tasks
+// and Total are pointers so that a page is written with both even when it is
+// empty.
 type SuccessResponse struct {
-	Id    int          `json:"id,omitempty"`
-	Tasks []tasks.Task `json:"tasks,omitempty"`
+	Id         int           `json:"id,omitempty"`
+	Tasks      *[]tasks.Task `json:"tasks,omitempty"`
+	NextCursor string        `json:"next_cursor,omitempty"`
+	Total      *int          `json:"total,omitempty"`
+}
+
+type OccurrencesResponse struct {
+	Dates []string `json:"dates"`
+}
+
+type AgendaDay struct {
+	Date  string       `json:"date"`
+	Tasks []tasks.Task `json:"tasks"`
+}
+
+type AgendaResponse struct {
+	Days []AgendaDay `json:"days"`
+}
+
+type HolidaysResponse struct {
+	Holidays []holidays.Holiday `json:"holidays"`
+}
+
+type CompletionsResponse struct {
+	Completions []completions.Completion `json:"completions"`
+}
+
+type UsersResponse struct {
+	Users []users.User `json:"users"`
+}
+
+type ListsResponse struct {
+	Lists []lists.List `json:"lists"`
+}
+
+type TagsResponse struct {
+	Tags []tags.Tag `json:"tags"`
+}
+
+type ChecklistResponse struct {
+	Items []checklists.Item `json:"items"`
+}
+
+type ToggleItemResponse struct {
+	Done bool `json:"done"`
+}
+
+type BlockersResponse struct {
+	Blockers []tasks.Task `json:"blockers"`
+}
+
+type AttachmentsResponse struct {
+	Attachments []attachments.Attachment `json:"attachments"`
 }
@@ -1,8 +1,9 @@
 package common
 
 import (
-	"errors"
 	"fmt"
+	"main/core/config"
+	"main/internal/models/tasks"
 	"main/pkg"
 	"time"
 )
@@ -11,43 +12,52 @@ func (t *AddTask) CheckTask() error {
 	if t.Title == "" {
 		return fmt.Errorf("не указан заголовок задачи")
 	}
+	today := pkg.Today(config.Location())
 	if t.Date == "" {
-		t.Date = time.Now().Format("20060102")
+		t.Date = today.Format("20060102")
 	}
 	date, err := time.Parse("20060102", t.Date)
 	if err != nil {
 		return fmt.Errorf("дата представлена в формате, отличном от 20060102")
 	}
+	if t.Time != "" {
+		if _, err = time.Parse("1504", t.Time); err != nil || len(t.Time) != 4 {
+			return fmt.Errorf("время представлено в формате, отличном от 1504")
+		}
+	}
 
-	if t.Repeat != "" && t.Repeat[0] != 'd' && t.Repeat[0] != 'w' && t.Repeat[0] != 'm' && t.Repeat[0] != 'y' {
-		return errors.New("неверное правило повторения")
+	if t.Priority != 0 && (t.Priority < tasks.PriorityHighest || t.Priority > tasks.PriorityLowest) {
+		return fmt.Errorf("приоритет должен быть от %d до %d", tasks.PriorityHighest, tasks.PriorityLowest)
 	}
 
-	if len(t.Repeat) > 0 {
-		if t.Repeat[0] != 'd' && t.Repeat[0] != 'w' && t.Repeat[0] != 'm' && t.Repeat[0] != 'y' {
-			return errors.New("неверное правило повторения")
-		}
-		if t.Repeat[0] == 'd' || t.Repeat[0] == 'w' || t.Repeat[0] == 'm' {
-			if len(t.Repeat) < 3 {
-				return errors.New("неверное правило повторения")
-			}
-		}
+	if _, err = pkg.ParseShift(t.Shift); err != nil {
+		return fmt.Errorf("неверный перенос на рабочий день: %v", err)
 	}
 
-	if date.Truncate(24 * time.Hour).Before(time.Now().Truncate(24 * time.Hour)) {
-		if t.Repeat == "" {
-			t.Date = time.Now().Format("20060102")
+	var rule pkg.RepeatRule
+	if t.Repeat != "" {
+		if rule, err = pkg.ParseRepeat(t.Repeat); err != nil {
+			return fmt.Errorf("неверное правило повторения: %v", err)
 		}
 	}
 
-	if date.Truncate(24 * time.Hour).Before(time.Now().Truncate(24 * time.Hour)) {
-		if t.Repeat != "" {
-			t.Date, err = pkg.NextDate(time.Now(), t.Date, t.Repeat)
+	t.Occurrence = 1
+	if date.Before(today) {
+		if t.Repeat == "" {
+			t.Date = today.Format("20060102")
+		} else {
+			next, steps, err := rule.Advance(date, today)
 			if err != nil {
 				return fmt.Errorf("ошибка при вычислении следующей даты: %v", err)
 			}
+			t.Date = next.Format("20060102")
+			t.Occurrence += steps
 		}
 	}
 
+	if !rule.Until.IsZero() && t.Date > rule.Until.Format("20060102") {
+		return fmt.Errorf("дата задачи позже даты окончания повторений")
+	}
+
 	return nil
 }
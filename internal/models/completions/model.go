@@ -0,0 +1,11 @@
+package completions
+
+type Completion struct {
+	ID          string `db:"id" json:"id"`
+	TaskID      string `db:"task_id" json:"task_id"`
+	Title       string `db:"title" json:"title"`
+	Date        string `db:"date" json:"date"`
+	CompletedAt string `db:"completed_at" json:"completed_at"`
+	Note        string `db:"note" json:"note,omitempty"`
+	UserID      int64  `db:"user_id" json:"-"`
+}
@@ -0,0 +1,6 @@
+package holidays
+
+type Holiday struct {
+	Date string `db:"date" json:"date" binding:"required"`
+	Name string `db:"name" json:"name"`
+}
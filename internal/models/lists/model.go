@@ -0,0 +1,9 @@
+package lists
+
+type List struct {
+	ID   string `db:"id" json:"id"`
+	Name string `db:"name" json:"name"`
+	// Tasks is the number of tasks in the list, not counting the trash.
+	Tasks  int   `db:"-" json:"tasks"`
+	UserID int64 `db:"user_id" json:"-"`
+}
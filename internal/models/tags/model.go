@@ -0,0 +1,10 @@
+package tags
+
+type Tag struct {
+	ID   string `db:"id" json:"id"`
+	Name string `db:"name" json:"name"`
+	// Tasks is the number of tasks marked with the tag, not counting the
+	// trash.
+	Tasks  int   `db:"-" json:"tasks"`
+	UserID int64 `db:"user_id" json:"-"`
+}
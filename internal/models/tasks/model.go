@@ -1,9 +1,33 @@
 package tasks
 
+// Task priorities run from PriorityHighest to PriorityLowest, which is the
+// priority of tasks that are not given one.
+const (
+	PriorityHighest = 1
+	PriorityLowest  = 4
+)
+
 type Task struct {
-	ID      string `db:"id" json:"id" binding:"required"`
-	Date    string `db:"date" json:"date" binding:"required"`
-	Title   string `db:"title" json:"title" binding:"required"`
-	Comment string `db:"comment" json:"comment"`
-	Repeat  string `db:"repeat" json:"repeat,omitempty"`
+	ID         string `db:"id" json:"id" binding:"required"`
+	Date       string `db:"date" json:"date" binding:"required"`
+	Time       string `db:"time" json:"time,omitempty"`
+	Title      string `db:"title" json:"title" binding:"required"`
+	Comment    string `db:"comment" json:"comment"`
+	Repeat     string `db:"repeat" json:"repeat,omitempty"`
+	Occurrence int    `db:"occurrence" json:"occurrence,string,omitempty"`
+	Shift      string `db:"shift" json:"shift,omitempty"`
+	Anchor     string `db:"anchor" json:"-"`
+	DeletedAt  string `db:"deleted_at" json:"deleted_at,omitempty"`
+	UserID     int64  `db:"user_id" json:"-"`
+	ListID     int64  `db:"list_id" json:"list_id,string,omitempty"`
+	// Priority is zero in a task given to UpdateTask to keep its priority.
+	Priority int `db:"priority" json:"priority,string,omitempty"`
+	// Tags are the names of the tags the task is marked with, sorted. A nil
+	// list given to UpdateTask keeps the tags of the task.
+	Tags []string `db:"-" json:"tags,omitempty"`
+	// Blocked is set for tasks with open blockers.
+	Blocked bool `db:"-" json:"blocked,omitempty"`
+	// Snippet is the matching part of the task with matches highlighted,
+	// set for search results only.
+	Snippet string `db:"-" json:"snippet,omitempty"`
 }
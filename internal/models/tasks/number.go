@@ -0,0 +1,52 @@
+package tasks
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+)
+
+// Number is an integer read from JSON given either as a number or as a string,
+// the form the API writes numbers in.
+type Number int64
+
+func (n *Number) UnmarshalJSON(data []byte) error {
+	if len(data) == 0 || data[0] != '"' {
+		var v int64
+		if err := json.Unmarshal(data, &v); err != nil {
+			return err
+		}
+		*n = Number(v)
+		return nil
+	}
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+	v, err := strconv.ParseInt(s, 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid number %q", s)
+	}
+	*n = Number(v)
+	return nil
+}
+
+// UnmarshalJSON reads a task accepting its occurrence, list id and priority
+// either as numbers or as strings.
+func (t *Task) UnmarshalJSON(data []byte) error {
+	type task Task
+	v := struct {
+		task
+		Occurrence Number `json:"occurrence"`
+		ListID     Number `json:"list_id"`
+		Priority   Number `json:"priority"`
+	}{task: task(*t), Occurrence: Number(t.Occurrence), ListID: Number(t.ListID), Priority: Number(t.Priority)}
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+	*t = Task(v.task)
+	t.Occurrence = int(v.Occurrence)
+	t.ListID = int64(v.ListID)
+	t.Priority = int(v.Priority)
+	return nil
+}
@@ -0,0 +1,8 @@
+package users
+
+type User struct {
+	ID           string `db:"id" json:"id"`
+	Login        string `db:"login" json:"login"`
+	PasswordHash string `db:"password_hash" json:"-"`
+	CreatedAt    string `db:"created_at" json:"created_at"`
+}
@@ -2,6 +2,7 @@ package router
 
 import (
 	"github.com/gofiber/fiber/v2"
+	"main/core/middleware"
 	"main/internal/controllers"
 )
 
@@ -10,15 +11,50 @@ func SetupRoutes(main *fiber.App) {
 	api := main.Group("/api")
 	{
 		api.Get("/nextdate", controllers.NextDate)
+		api.Get("/occurrences", controllers.Occurrences)
 		api.Post("/signin", controllers.SignIn)
-		authGroup := api.Group("")
+		authGroup := api.Group("", middleware.AuthMiddleware)
 		{
 			authGroup.Post("/task", controllers.AddTask)
 			authGroup.Get("/task", controllers.GetTask)
 			authGroup.Put("/task", controllers.UpdateTask)
 			authGroup.Delete("/task", controllers.DeleteTask)
 			authGroup.Post("/task/done", controllers.DoneTask)
+			authGroup.Post("/task/move", controllers.MoveTask)
+			authGroup.Get("/task/history", controllers.TaskHistory)
+			authGroup.Get("/task/checklist", controllers.GetChecklist)
+			authGroup.Post("/task/checklist", controllers.AddItem)
+			authGroup.Put("/task/checklist", controllers.ReorderChecklist)
+			authGroup.Delete("/task/checklist", controllers.DeleteItem)
+			authGroup.Post("/task/checklist/toggle", controllers.ToggleItem)
+			authGroup.Get("/task/dependencies", controllers.GetBlockers)
+			authGroup.Post("/task/dependencies", controllers.AddDependency)
+			authGroup.Delete("/task/dependencies", controllers.DeleteDependency)
+			authGroup.Get("/task/attachments", controllers.GetAttachments)
+			authGroup.Post("/task/attachments", controllers.AddAttachment)
+			authGroup.Delete("/task/attachments", controllers.DeleteAttachment)
+			authGroup.Get("/task/attachments/file", controllers.GetAttachmentFile)
 			authGroup.Get("/tasks", controllers.GetTasks)
+			authGroup.Get("/trash", controllers.GetTrash)
+			authGroup.Post("/trash/restore", controllers.RestoreTask)
+			authGroup.Delete("/trash", controllers.PurgeTrash)
+			authGroup.Get("/agenda", controllers.GetAgenda)
+			authGroup.Get("/completions", controllers.GetCompletions)
+			authGroup.Get("/lists", controllers.GetLists)
+			authGroup.Post("/lists", controllers.AddList)
+			authGroup.Put("/lists", controllers.UpdateList)
+			authGroup.Delete("/lists", controllers.DeleteList)
+			authGroup.Get("/tags", controllers.GetTags)
+			authGroup.Post("/tags", controllers.AddTag)
+			authGroup.Put("/tags", controllers.UpdateTag)
+			authGroup.Delete("/tags", controllers.DeleteTag)
+			authGroup.Post("/tags/merge", controllers.MergeTags)
+			authGroup.Get("/holidays", controllers.GetHolidays)
+			authGroup.Post("/holidays", middleware.AdminMiddleware, controllers.AddHoliday)
+			authGroup.Delete("/holidays", middleware.AdminMiddleware, controllers.DeleteHoliday)
+			authGroup.Get("/users", middleware.AdminMiddleware, controllers.GetUsers)
+			authGroup.Post("/users", middleware.AdminMiddleware, controllers.AddUser)
+			authGroup.Delete("/users", middleware.AdminMiddleware, controllers.DeleteUser)
 		}
 	}
 }
@@ -0,0 +1,145 @@
+package pkg
+
+import (
+	"fmt"
+	"time"
+)
+
+// Shift tells which way a task moves when it falls on a weekend or holiday.
+type Shift string
+
+const (
+	ShiftNone Shift = ""
+	ShiftNext Shift = "next"
+	ShiftPrev Shift = "prev"
+)
+
+// maxShiftDays guards against calendars with no business days left.
+const maxShiftDays = 366
+
+func ParseShift(shift string) (Shift, error) {
+	switch Shift(shift) {
+	case ShiftNone, ShiftNext, ShiftPrev:
+		return Shift(shift), nil
+	}
+	return ShiftNone, fmt.Errorf("unknown shift %q", shift)
+}
+
+// Calendar tells business days from weekends and holidays. A nil Calendar
+// knows only weekends.
+type Calendar struct {
+	holidays map[string]bool
+}
+
+func NewCalendar(holidays []time.Time) *Calendar {
+	c := &Calendar{holidays: make(map[string]bool, len(holidays))}
+	for _, day := range holidays {
+		c.holidays[day.Format("20060102")] = true
+	}
+	return c
+}
+
+func (c *Calendar) IsBusinessDay(t time.Time) bool {
+	if t.Weekday() == time.Saturday || t.Weekday() == time.Sunday {
+		return false
+	}
+	return c == nil || !c.holidays[t.Format("20060102")]
+}
+
+// Shift moves t to the closest business day in the given direction.
+func (c *Calendar) Shift(t time.Time, shift Shift) time.Time {
+	step := 0
+	switch shift {
+	case ShiftNext:
+		step = 1
+	case ShiftPrev:
+		step = -1
+	default:
+		return t
+	}
+	for i := 0; i < maxShiftDays && !c.IsBusinessDay(t); i++ {
+		t = t.AddDate(0, 0, step)
+	}
+	return t
+}
+
+// ShiftedOccurrences is OccurrencesBetween for a task that is shifted to
+// business days. It includes occurrences just outside from and to that are
+// shifted into them, and leaves out those shifted out of them or onto the
+// same day as the previous one.
+func (c *Calendar) ShiftedOccurrences(date string, repeat string, from time.Time, to time.Time, limit int, shift Shift) ([]time.Time, error) {
+	start, end := from, to
+	switch shift {
+	case ShiftNext:
+		for i := 0; i < maxShiftDays && !c.IsBusinessDay(start.AddDate(0, 0, -1)); i++ {
+			start = start.AddDate(0, 0, -1)
+		}
+	case ShiftPrev:
+		for i := 0; !end.IsZero() && i < maxShiftDays && !c.IsBusinessDay(end.AddDate(0, 0, 1)); i++ {
+			end = end.AddDate(0, 0, 1)
+		}
+	default:
+		return OccurrencesBetween(date, repeat, from, to, limit)
+	}
+
+	it, err := NewOccurrences(date, repeat, start)
+	if err != nil {
+		return nil, err
+	}
+	var result []time.Time
+	for it.Next() {
+		if !end.IsZero() && it.Date().After(end) {
+			break
+		}
+		shifted := c.Shift(it.Date(), shift)
+		if shifted.Before(from) || (!to.IsZero() && shifted.After(to)) {
+			continue
+		}
+		if len(result) > 0 && result[len(result)-1].Equal(shifted) {
+			continue
+		}
+		result = append(result, shifted)
+		if limit > 0 && len(result) >= limit {
+			break
+		}
+	}
+	return result, it.Err()
+}
+
+// Advance is RepeatRule.Advance for a task that is shifted to business days.
+// It returns the shifted date, the occurrence it was shifted from, and how
+// many occurrences that is past date. Since shifting back can land on or
+// before now, such occurrences are skipped.
+func (c *Calendar) Advance(rule RepeatRule, date time.Time, now time.Time, shift Shift) (time.Time, time.Time, int, error) {
+	base, steps, err := rule.Advance(date, now)
+	for err == nil {
+		if next := c.Shift(base, shift); next.After(now) {
+			return next, base, steps, nil
+		}
+		if rule.Count > 0 && steps+1 >= rule.Count {
+			return time.Time{}, time.Time{}, 0, ErrRepeatFinished
+		}
+		base, err = rule.Next(base)
+		steps++
+	}
+	return time.Time{}, time.Time{}, 0, err
+}
+
+// NextDate is pkg.NextDate for a task that is shifted to business days.
+func (c *Calendar) NextDate(now time.Time, date string, repeat string, shift Shift) (string, error) {
+	rule, err := ParseRepeat(repeat)
+	if err != nil {
+		return "", err
+	}
+
+	t, err := time.Parse("20060102", date)
+	if err != nil {
+		return "", err
+	}
+
+	next, _, _, err := c.Advance(rule, t, now, shift)
+	if err != nil {
+		return "", err
+	}
+	return next.Format("20060102"), nil
+}
@@ -0,0 +1,149 @@
+package pkg
+
+import (
+	"bufio"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+type Holiday struct {
+	Date time.Time
+	Name string
+}
+
+// ReadHolidays loads holidays from an iCalendar (.ics) file or from a CSV
+// file with a YYYYMMDD date and an optional name on each line.
+func ReadHolidays(path string) ([]Holiday, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	if strings.EqualFold(filepath.Ext(path), ".ics") {
+		return ReadHolidaysICS(f)
+	}
+	return ReadHolidaysCSV(f)
+}
+
+func ReadHolidaysCSV(r io.Reader) ([]Holiday, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	var result []Holiday
+	for line := 1; ; line++ {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		if len(record) == 0 || record[0] == "" || strings.HasPrefix(record[0], "#") {
+			continue
+		}
+		date, err := parseHolidayDate(record[0])
+		if err != nil {
+			// The first line may be a header.
+			if line == 1 {
+				continue
+			}
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		holiday := Holiday{Date: date}
+		if len(record) > 1 {
+			holiday.Name = record[1]
+		}
+		result = append(result, holiday)
+	}
+	return result, nil
+}
+
+// ReadHolidaysICS reads all-day events from an iCalendar file. Events that
+// span several days produce a holiday for each of them.
+func ReadHolidaysICS(r io.Reader) ([]Holiday, error) {
+	var lines []string
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		// Long lines are folded by starting continuation lines with a space.
+		if len(lines) > 0 && (strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t")) {
+			lines[len(lines)-1] += line[1:]
+			continue
+		}
+		lines = append(lines, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	var result []Holiday
+	var start, end time.Time
+	var name string
+	inEvent := false
+	for _, line := range lines {
+		key, value, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		key, _, _ = strings.Cut(key, ";")
+		switch strings.ToUpper(key) {
+		case "BEGIN":
+			if value == "VEVENT" {
+				inEvent = true
+				start, end, name = time.Time{}, time.Time{}, ""
+			}
+		case "DTSTART", "DTEND":
+			if !inEvent {
+				continue
+			}
+			date, err := parseHolidayDate(value)
+			if err != nil {
+				return nil, err
+			}
+			if strings.EqualFold(key, "DTSTART") {
+				start = date
+			} else {
+				end = date
+			}
+		case "SUMMARY":
+			name = strings.ReplaceAll(value, `\,`, ",")
+		case "END":
+			if value != "VEVENT" || !inEvent {
+				continue
+			}
+			inEvent = false
+			if start.IsZero() {
+				return nil, errors.New("event without DTSTART")
+			}
+			// DTEND is exclusive.
+			if !end.After(start) {
+				end = start.AddDate(0, 0, 1)
+			}
+			for day := start; day.Before(end); day = day.AddDate(0, 0, 1) {
+				result = append(result, Holiday{Date: day, Name: name})
+			}
+		}
+	}
+	return result, nil
+}
+
+func parseHolidayDate(value string) (time.Time, error) {
+	value = strings.TrimSpace(value)
+	if len(value) > 8 && value[8] == 'T' {
+		value = value[:8]
+	}
+	for _, layout := range []string{"20060102", "2006-01-02"} {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid holiday date %q", value)
+}
@@ -0,0 +1,122 @@
+package pkg
+
+import (
+	"errors"
+	"time"
+)
+
+// Occurrences iterates over the dates a task lands on. The task date itself is
+// the first occurrence; a task without a repeat rule has only that one.
+//
+//	it, err := pkg.NewOccurrences("20240101", "d 7", from)
+//	for it.Next() {
+//		date := it.Date()
+//	}
+//	err = it.Err()
+type Occurrences struct {
+	rule    *RepeatRule
+	from    time.Time
+	cur     time.Time
+	n       int
+	started bool
+	done    bool
+	err     error
+}
+
+// NewOccurrences returns an iterator over the occurrences of a task that fall
+// on or after from.
+func NewOccurrences(date string, repeat string, from time.Time) (*Occurrences, error) {
+	start, err := time.Parse("20060102", date)
+	if err != nil {
+		return nil, err
+	}
+	o := &Occurrences{from: from, cur: start}
+	if repeat == "" {
+		return o, nil
+	}
+
+	rule, err := ParseRepeat(repeat)
+	if err != nil {
+		return nil, err
+	}
+	o.rule = &rule
+
+	// Rules without COUNT can jump straight to the window; COUNT has to be
+	// counted from the task date, which is cheap since it is bounded anyway.
+	if start.Before(from) && rule.Count == 0 {
+		o.cur, err = rule.NextDate(start, from.AddDate(0, 0, -1))
+		if err != nil {
+			if !errors.Is(err, ErrRepeatFinished) {
+				return nil, err
+			}
+			o.done = true
+		}
+	}
+
+	return o, nil
+}
+
+// Next advances the iterator and reports whether there is another occurrence.
+func (o *Occurrences) Next() bool {
+	for !o.done {
+		if o.started && !o.advance() {
+			return false
+		}
+		o.started = true
+		o.n++
+		if !o.cur.Before(o.from) {
+			return true
+		}
+	}
+	return false
+}
+
+// Date returns the current occurrence.
+func (o *Occurrences) Date() time.Time {
+	return o.cur
+}
+
+// Err returns the error, if any, that stopped the iteration.
+func (o *Occurrences) Err() error {
+	return o.err
+}
+
+func (o *Occurrences) advance() bool {
+	if o.rule == nil || (o.rule.Count > 0 && o.n >= o.rule.Count) {
+		o.done = true
+		return false
+	}
+
+	next, err := o.rule.Next(o.cur)
+	if err != nil {
+		o.done = true
+		if !errors.Is(err, ErrRepeatFinished) {
+			o.err = err
+		}
+		return false
+	}
+	o.cur = next
+	return true
+}
+
+// OccurrencesBetween returns up to limit occurrences of a task between from
+// and to inclusive. A zero to or limit means no bound.
+func OccurrencesBetween(date string, repeat string, from time.Time, to time.Time, limit int) ([]time.Time, error) {
+	it, err := NewOccurrences(date, repeat, from)
+	if err != nil {
+		return nil, err
+	}
+
+	var result []time.Time
+	for it.Next() {
+		if !to.IsZero() && it.Date().After(to) {
+			break
+		}
+		result = append(result, it.Date())
+		if limit > 0 && len(result) >= limit {
+			break
+		}
+	}
+
+	return result, it.Err()
+}
@@ -0,0 +1,372 @@
+package pkg
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type RepeatKind byte
+
+const (
+	RepeatDays   RepeatKind = 'd'
+	RepeatWeeks  RepeatKind = 'w'
+	RepeatMonths RepeatKind = 'm'
+	RepeatYears  RepeatKind = 'y'
+	RepeatRRule  RepeatKind = 'R'
+)
+
+const (
+	maxDaysInterval  = 400
+	maxWeeksInterval = 52
+)
+
+// daysInMonth is the longest each month can be, used to reject month rules
+// that could never produce a date.
+var daysInMonth = [...]int{31, 29, 31, 30, 31, 30, 31, 31, 30, 31, 30, 31}
+
+// RepeatRule is a parsed task repeat rule.
+type RepeatRule struct {
+	Kind RepeatKind
+	// Interval is the number of days between occurrences for "d" rules and
+	// the number of weeks between active weeks for "w" rules.
+	Interval int
+	// WeekDays holds 1 (Monday) to 7 (Sunday) for "w" rules.
+	WeekDays []int
+	// MonthDays holds 1 to 31, -1 (last day) and -2 (day before last) for "m" rules.
+	MonthDays []int
+	// NthWeekDays holds "m" rule entries like 2#2 (second Tuesday) or
+	// 5#-1 (last Friday).
+	NthWeekDays []WeekdayNum
+	// Months optionally restricts "m" rules to the given months.
+	Months []int
+	RRule  RRule
+	// Count and Until end the series after Count occurrences or after the
+	// Until date. They come from a "count N" or "until YYYYMMDD" suffix, or
+	// from COUNT and UNTIL of an RRULE.
+	Count int
+	Until time.Time
+}
+
+func ParseRepeat(repeat string) (RepeatRule, error) {
+	if repeat == "" {
+		return RepeatRule{}, errors.New("repeat is required")
+	}
+	if IsRRule(repeat) {
+		rule, err := ParseRRule(repeat)
+		if err != nil {
+			return RepeatRule{}, err
+		}
+		result := RepeatRule{Kind: RepeatRRule, RRule: rule, Count: rule.Count, Until: rule.Until}
+		result.RRule.Count, result.RRule.Until = 0, time.Time{}
+		return result, nil
+	}
+
+	parts := strings.Split(repeat, " ")
+	var count int
+	var until time.Time
+	if n := len(parts); n >= 3 {
+		var err error
+		switch parts[n-2] {
+		case "count":
+			count, err = strconv.Atoi(parts[n-1])
+			if err != nil || count < 1 {
+				return RepeatRule{}, fmt.Errorf("invalid occurrence count %q", parts[n-1])
+			}
+			parts = parts[:n-2]
+		case "until":
+			until, err = time.Parse("20060102", parts[n-1])
+			if err != nil {
+				return RepeatRule{}, fmt.Errorf("invalid end date %q", parts[n-1])
+			}
+			parts = parts[:n-2]
+		}
+	}
+
+	rule, err := parseRepeatParts(parts)
+	if err != nil {
+		return RepeatRule{}, err
+	}
+	rule.Count, rule.Until = count, until
+	return rule, nil
+}
+
+func parseRepeatParts(parts []string) (RepeatRule, error) {
+	switch parts[0] {
+	case "y":
+		if len(parts) != 1 {
+			return RepeatRule{}, errors.New("yearly rule takes no arguments")
+		}
+		return RepeatRule{Kind: RepeatYears}, nil
+	case "d":
+		if len(parts) != 2 {
+			return RepeatRule{}, errors.New("daily rule requires exactly one interval")
+		}
+		interval, err := strconv.Atoi(parts[1])
+		if err != nil {
+			return RepeatRule{}, fmt.Errorf("invalid days interval %q", parts[1])
+		}
+		if interval < 1 || interval > maxDaysInterval {
+			return RepeatRule{}, fmt.Errorf("days interval %d is out of range 1..%d", interval, maxDaysInterval)
+		}
+		return RepeatRule{Kind: RepeatDays, Interval: interval}, nil
+	case "w":
+		if len(parts) != 2 && len(parts) != 3 {
+			return RepeatRule{}, errors.New("weekly rule requires a list of week days and an optional interval")
+		}
+		weekDays, err := parseRepeatList(parts[1], "week day", validWeekDay)
+		if err != nil {
+			return RepeatRule{}, err
+		}
+		rule := RepeatRule{Kind: RepeatWeeks, WeekDays: weekDays, Interval: 1}
+		if len(parts) == 3 {
+			rule.Interval, err = strconv.Atoi(parts[2])
+			if err != nil {
+				return RepeatRule{}, fmt.Errorf("invalid weeks interval %q", parts[2])
+			}
+			if rule.Interval < 1 || rule.Interval > maxWeeksInterval {
+				return RepeatRule{}, fmt.Errorf("weeks interval %d is out of range 1..%d", rule.Interval, maxWeeksInterval)
+			}
+		}
+		return rule, nil
+	case "m":
+		if len(parts) != 2 && len(parts) != 3 {
+			return RepeatRule{}, errors.New("monthly rule requires a list of days and an optional list of months")
+		}
+		rule := RepeatRule{Kind: RepeatMonths}
+		if err := rule.parseMonthDays(parts[1]); err != nil {
+			return RepeatRule{}, err
+		}
+		var err error
+		if len(parts) == 3 {
+			rule.Months, err = parseRepeatList(parts[2], "month", func(month int) bool {
+				return month >= 1 && month <= 12
+			})
+			if err != nil {
+				return RepeatRule{}, err
+			}
+			for _, month := range rule.Months {
+				if !rule.monthHasDay(month) {
+					return RepeatRule{}, fmt.Errorf("no listed day occurs in month %d", month)
+				}
+			}
+		}
+		return rule, nil
+	}
+
+	return RepeatRule{}, fmt.Errorf("unknown repeat rule %q", parts[0])
+}
+
+func parseRepeatList(list string, name string, valid func(int) bool) ([]int, error) {
+	var result []int
+	for _, item := range strings.Split(list, ",") {
+		n, err := strconv.Atoi(item)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s %q", name, item)
+		}
+		if !valid(n) {
+			return nil, fmt.Errorf("incorrect %s: %d", name, n)
+		}
+		result = append(result, n)
+	}
+	return result, nil
+}
+
+func validWeekDay(day int) bool {
+	return day >= 1 && day <= 7
+}
+
+// parseMonthDays parses a list of month days where each entry is either a day
+// number or a weekday#n entry.
+func (r *RepeatRule) parseMonthDays(list string) error {
+	for _, item := range strings.Split(list, ",") {
+		weekDay, nth, ok := strings.Cut(item, "#")
+		if !ok {
+			day, err := strconv.Atoi(item)
+			if err != nil {
+				return fmt.Errorf("invalid month day %q", item)
+			}
+			if (day < 1 || day > 31) && day != -1 && day != -2 {
+				return fmt.Errorf("incorrect month day: %d", day)
+			}
+			r.MonthDays = append(r.MonthDays, day)
+			continue
+		}
+
+		day, err := strconv.Atoi(weekDay)
+		if err != nil || !validWeekDay(day) {
+			return fmt.Errorf("invalid week day in %q", item)
+		}
+		n, err := strconv.Atoi(nth)
+		if err != nil || n == 0 || n < -5 || n > 5 {
+			return fmt.Errorf("invalid week number in %q: must be 1..5 or -1..-5", item)
+		}
+		r.NthWeekDays = append(r.NthWeekDays, WeekdayNum{N: n, Day: time.Weekday(day % 7)})
+	}
+	return nil
+}
+
+func (r RepeatRule) monthHasDay(month int) bool {
+	if len(r.NthWeekDays) > 0 {
+		return true
+	}
+	for _, day := range r.MonthDays {
+		if day < 0 || day <= daysInMonth[month-1] {
+			return true
+		}
+	}
+	return false
+}
+
+func (r RepeatRule) String() string {
+	if r.Kind == RepeatRRule {
+		rule := r.RRule
+		rule.Count, rule.Until = r.Count, r.Until
+		return rule.String()
+	}
+
+	result := r.baseString()
+	if r.Count > 0 {
+		result += " count " + strconv.Itoa(r.Count)
+	}
+	if !r.Until.IsZero() {
+		result += " until " + r.Until.Format("20060102")
+	}
+	return result
+}
+
+func (r RepeatRule) baseString() string {
+	switch r.Kind {
+	case RepeatYears:
+		return "y"
+	case RepeatDays:
+		return "d " + strconv.Itoa(r.Interval)
+	case RepeatWeeks:
+		if r.Interval > 1 {
+			return "w " + joinInts(r.WeekDays) + " " + strconv.Itoa(r.Interval)
+		}
+		return "w " + joinInts(r.WeekDays)
+	case RepeatMonths:
+		days := make([]string, 0, len(r.MonthDays)+len(r.NthWeekDays))
+		for _, day := range r.MonthDays {
+			days = append(days, strconv.Itoa(day))
+		}
+		for _, wd := range r.NthWeekDays {
+			days = append(days, strconv.Itoa((int(wd.Day)+6)%7+1)+"#"+strconv.Itoa(wd.N))
+		}
+		if len(r.Months) > 0 {
+			return "m " + strings.Join(days, ",") + " " + joinInts(r.Months)
+		}
+		return "m " + strings.Join(days, ",")
+	}
+	return ""
+}
+
+// Next returns the occurrence that follows after, which is treated as an
+// occurrence of the rule itself. Count is not applied here since it depends
+// on how many occurrences came before; ErrRepeatFinished is returned once
+// Until is passed.
+func (r RepeatRule) Next(after time.Time) (time.Time, error) {
+	next, err := r.next(after)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if !r.Until.IsZero() && next.After(r.Until) {
+		return time.Time{}, ErrRepeatFinished
+	}
+	return next, nil
+}
+
+func (r RepeatRule) next(after time.Time) (time.Time, error) {
+	switch r.Kind {
+	case RepeatYears:
+		return after.AddDate(1, 0, 0), nil
+	case RepeatDays:
+		return after.AddDate(0, 0, r.Interval), nil
+	case RepeatWeeks:
+		// Finish the current week, then skip to the next active one. Weeks
+		// start on Monday.
+		t := after.AddDate(0, 0, 1)
+		for ; t.Weekday() != time.Monday; t = t.AddDate(0, 0, 1) {
+			if r.matchWeekDay(t) {
+				return t, nil
+			}
+		}
+		if r.Interval > 1 {
+			t = t.AddDate(0, 0, 7*(r.Interval-1))
+		}
+		for ; ; t = t.AddDate(0, 0, 1) {
+			if r.matchWeekDay(t) {
+				return t, nil
+			}
+		}
+	case RepeatMonths:
+		for t := after.AddDate(0, 0, 1); ; t = t.AddDate(0, 0, 1) {
+			if r.matchMonth(t) && r.matchMonthDay(t) {
+				return t, nil
+			}
+		}
+	case RepeatRRule:
+		return r.RRule.next(after, after)
+	}
+	return time.Time{}, errors.New("empty repeat rule")
+}
+
+// NextDate returns the first occurrence after now of a task currently
+// scheduled on date. Unlike Next, Count is applied, counting date as the
+// first occurrence.
+func (r RepeatRule) NextDate(date time.Time, now time.Time) (time.Time, error) {
+	next, _, err := r.Advance(date, now)
+	return next, err
+}
+
+// Advance is NextDate that also reports how many occurrences the result is
+// past date, so callers can keep track of Count across calls.
+func (r RepeatRule) Advance(date time.Time, now time.Time) (time.Time, int, error) {
+	for steps := 1; ; steps++ {
+		next, err := r.Next(date)
+		if err != nil {
+			return time.Time{}, 0, err
+		}
+		if r.Count > 0 && steps >= r.Count {
+			return time.Time{}, 0, ErrRepeatFinished
+		}
+		if next.After(now) {
+			return next, steps, nil
+		}
+		date = next
+	}
+}
+
+func (r RepeatRule) matchWeekDay(t time.Time) bool {
+	for _, day := range r.WeekDays {
+		if time.Weekday(day%7) == t.Weekday() {
+			return true
+		}
+	}
+	return false
+}
+
+func (r RepeatRule) matchMonth(t time.Time) bool {
+	if len(r.Months) == 0 {
+		return true
+	}
+	for _, month := range r.Months {
+		if time.Month(month) == t.Month() {
+			return true
+		}
+	}
+	return false
+}
+
+func (r RepeatRule) matchMonthDay(t time.Time) bool {
+	last := time.Date(t.Year(), t.Month()+1, 0, 0, 0, 0, 0, time.UTC).Day()
+	for _, day := range r.MonthDays {
+		if day == t.Day() || (day < 0 && last+day+1 == t.Day()) {
+			return true
+		}
+	}
+	return matchNthWeekday(r.NthWeekDays, t.Weekday(), (t.Day()-1)/7+1, (last-t.Day())/7+1)
+}
@@ -0,0 +1,179 @@
+package pkg
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func date(t *testing.T, s string) time.Time {
+	t.Helper()
+	d, err := time.Parse("20060102", s)
+	require.NoError(t, err)
+	return d
+}
+
+func TestParseRepeatRoundTrip(t *testing.T) {
+	for _, repeat := range []string{
+		"y",
+		"d 1",
+		"d 400",
+		"w 7",
+		"w 1,3,5",
+		"w 1,4 2",
+		"w 6 52",
+		"m 1",
+		"m -1,-2",
+		"m 1,15,31 1,3,12",
+		"m 2#2",
+		"m 5#-1",
+		"m 1,1#1,7#-2 6,7",
+		"d 3 count 5",
+		"w 2 2 count 1",
+		"m 31 until 20241231",
+		"y until 20300101",
+		"RRULE:FREQ=DAILY;INTERVAL=2;COUNT=3",
+	} {
+		rule, err := ParseRepeat(repeat)
+		if assert.NoError(t, err, repeat) {
+			assert.Equal(t, repeat, rule.String())
+		}
+	}
+}
+
+func TestParseRepeat(t *testing.T) {
+	tbl := []struct {
+		repeat string
+		want   RepeatRule
+	}{
+		{"w 1,4", RepeatRule{Kind: RepeatWeeks, WeekDays: []int{1, 4}, Interval: 1}},
+		{"w 1,4 2", RepeatRule{Kind: RepeatWeeks, WeekDays: []int{1, 4}, Interval: 2}},
+		{"m 2#2,5#-1", RepeatRule{Kind: RepeatMonths, NthWeekDays: []WeekdayNum{
+			{N: 2, Day: time.Tuesday}, {N: -1, Day: time.Friday}}}},
+		{"m 7#1", RepeatRule{Kind: RepeatMonths, NthWeekDays: []WeekdayNum{{N: 1, Day: time.Sunday}}}},
+		{"d 2 count 3", RepeatRule{Kind: RepeatDays, Interval: 2, Count: 3}},
+		{"y until 20300101", RepeatRule{Kind: RepeatYears, Until: time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)}},
+	}
+	for _, v := range tbl {
+		rule, err := ParseRepeat(v.repeat)
+		if assert.NoError(t, err, v.repeat) {
+			assert.Equal(t, v.want, rule, v.repeat)
+		}
+	}
+}
+
+func TestParseRepeatErrors(t *testing.T) {
+	tbl := []struct {
+		repeat string
+		err    string
+	}{
+		{"", "repeat is required"},
+		{"x", `unknown repeat rule "x"`},
+		{"y 1", "yearly rule takes no arguments"},
+		{"d", "daily rule requires exactly one interval"},
+		{"d 1 2", "daily rule requires exactly one interval"},
+		{"d x", `invalid days interval "x"`},
+		{"d 0", "days interval 0 is out of range 1..400"},
+		{"d 401", "days interval 401 is out of range 1..400"},
+		{"w", "weekly rule requires a list of week days and an optional interval"},
+		{"w ", `invalid week day ""`},
+		{"w 1,,2", `invalid week day ""`},
+		{"w 8", "incorrect week day: 8"},
+		{"w 0", "incorrect week day: 0"},
+		{"w 1 x", `invalid weeks interval "x"`},
+		{"w 1 0", "weeks interval 0 is out of range 1..52"},
+		{"w 1 53", "weeks interval 53 is out of range 1..52"},
+		{"m", "monthly rule requires a list of days and an optional list of months"},
+		{"m ", `invalid month day ""`},
+		{"m 1 2 3", "monthly rule requires a list of days and an optional list of months"},
+		{"m 32", "incorrect month day: 32"},
+		{"m 0", "incorrect month day: 0"},
+		{"m -3", "incorrect month day: -3"},
+		{"m 1 13", "incorrect month: 13"},
+		{"m 1 ", `invalid month ""`},
+		{"m 30 2", "no listed day occurs in month 2"},
+		{"m 31 4,6", "no listed day occurs in month 4"},
+		{"m 8#1", `invalid week day in "8#1"`},
+		{"m 1#0", `invalid week number in "1#0": must be 1..5 or -1..-5`},
+		{"m 1#6", `invalid week number in "1#6": must be 1..5 or -1..-5`},
+		{"d 1 count 0", `invalid occurrence count "0"`},
+		{"d 1 count x", `invalid occurrence count "x"`},
+		{"d 1 until 2024", `invalid end date "2024"`},
+		{"d 1 until", "daily rule requires exactly one interval"},
+	}
+	for _, v := range tbl {
+		_, err := ParseRepeat(v.repeat)
+		if assert.Error(t, err, v.repeat) {
+			assert.Equal(t, v.err, err.Error(), v.repeat)
+		}
+	}
+}
+
+func TestRepeatNext(t *testing.T) {
+	tbl := []struct {
+		after  string
+		repeat string
+		want   string
+	}{
+		{"20240131", "d 1", "20240201"},
+		{"20240228", "d 1", "20240229"},
+		{"20230228", "d 1", "20230301"},
+		{"20241231", "d 1", "20250101"},
+		{"20240229", "y", "20250301"},
+		{"20230301", "y", "20240301"},
+		{"20240131", "w 1", "20240205"},
+		{"20240130", "w 2,3", "20240131"},
+		{"20240101", "w 1 2", "20240115"},
+		{"20240131", "w 3,4 2", "20240201"},
+		{"20240201", "w 3,4 2", "20240214"},
+		{"20240130", "m -1", "20240131"},
+		{"20240131", "m -1", "20240229"},
+		{"20230131", "m -1", "20230228"},
+		{"20240201", "m -2", "20240228"},
+		{"20240229", "m -2", "20240330"},
+		{"20240131", "m 31", "20240331"},
+		{"20240430", "m 31", "20240531"},
+		{"20240131", "m 30", "20240330"},
+		{"20240301", "m 29 2", "20280229"},
+		{"20240101", "m 2#2", "20240109"},
+		{"20240101", "m 5#-1", "20240126"},
+		{"20240126", "m 5#-1", "20240223"},
+		{"20240101", "m 4#5", "20240229"},
+		{"20240229", "m 4#5", "20240530"},
+		{"20240101", "m 1 3", "20240301"},
+	}
+	for _, v := range tbl {
+		rule, err := ParseRepeat(v.repeat)
+		require.NoError(t, err, v.repeat)
+		next, err := rule.Next(date(t, v.after))
+		if assert.NoError(t, err, v.repeat) {
+			assert.Equal(t, v.want, next.Format("20060102"), "%s after %s", v.repeat, v.after)
+		}
+	}
+}
+
+func TestRepeatEnd(t *testing.T) {
+	rule, err := ParseRepeat("d 1 until 20240302")
+	require.NoError(t, err)
+	next, err := rule.Next(date(t, "20240301"))
+	require.NoError(t, err)
+	assert.Equal(t, "20240302", next.Format("20060102"))
+	_, err = rule.Next(date(t, "20240302"))
+	assert.ErrorIs(t, err, ErrRepeatFinished)
+
+	// Count is applied by NextDate and Advance, counting the starting date
+	// as the first occurrence.
+	rule, err = ParseRepeat("d 1 count 4")
+	require.NoError(t, err)
+	next, steps, err := rule.Advance(date(t, "20240101"), date(t, "20240102"))
+	require.NoError(t, err)
+	assert.Equal(t, "20240103", next.Format("20060102"))
+	assert.Equal(t, 2, steps)
+	next, err = rule.NextDate(date(t, "20240101"), date(t, "20240103"))
+	require.NoError(t, err)
+	assert.Equal(t, "20240104", next.Format("20060102"))
+	_, err = rule.NextDate(date(t, "20240101"), date(t, "20240104"))
+	assert.ErrorIs(t, err, ErrRepeatFinished)
+}
@@ -0,0 +1,449 @@
+package pkg
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const rrulePrefix = "RRULE:"
+
+// maxRRuleYears bounds how far ahead an RRULE is expanded while looking for
+// the next occurrence, so rules that can never match don't loop forever.
+const maxRRuleYears = 400
+
+var ErrRepeatFinished = errors.New("repeat rule has no more occurrences")
+
+type Frequency string
+
+const (
+	Daily   Frequency = "DAILY"
+	Weekly  Frequency = "WEEKLY"
+	Monthly Frequency = "MONTHLY"
+	Yearly  Frequency = "YEARLY"
+)
+
+var rruleWeekdays = map[string]time.Weekday{
+	"MO": time.Monday,
+	"TU": time.Tuesday,
+	"WE": time.Wednesday,
+	"TH": time.Thursday,
+	"FR": time.Friday,
+	"SA": time.Saturday,
+	"SU": time.Sunday,
+}
+
+// WeekdayNum is a BYDAY entry: a weekday with an optional ordinal,
+// e.g. 2TU (second Tuesday) or -1FR (last Friday). N is 0 for plain weekdays.
+type WeekdayNum struct {
+	N   int
+	Day time.Weekday
+}
+
+// RRule is the supported subset of an RFC 5545 recurrence rule. The task date
+// is used as DTSTART.
+type RRule struct {
+	Freq       Frequency
+	Interval   int
+	ByDay      []WeekdayNum
+	ByMonthDay []int
+	ByMonth    []int
+	BySetPos   []int
+	Count      int
+	Until      time.Time
+}
+
+func IsRRule(repeat string) bool {
+	return strings.HasPrefix(strings.ToUpper(repeat), rrulePrefix)
+}
+
+func ParseRRule(repeat string) (RRule, error) {
+	if !IsRRule(repeat) {
+		return RRule{}, errors.New("rrule must start with RRULE:")
+	}
+	r := RRule{Interval: 1}
+	seen := make(map[string]bool)
+
+	for _, part := range strings.Split(repeat[len(rrulePrefix):], ";") {
+		if part == "" {
+			continue
+		}
+		name, value, ok := strings.Cut(part, "=")
+		if !ok || value == "" {
+			return RRule{}, fmt.Errorf("invalid rrule part: %q", part)
+		}
+		name = strings.ToUpper(strings.TrimSpace(name))
+		value = strings.ToUpper(strings.TrimSpace(value))
+		if seen[name] {
+			return RRule{}, fmt.Errorf("duplicate rrule part: %s", name)
+		}
+		seen[name] = true
+
+		var err error
+		switch name {
+		case "FREQ":
+			switch Frequency(value) {
+			case Daily, Weekly, Monthly, Yearly:
+				r.Freq = Frequency(value)
+			default:
+				return RRule{}, fmt.Errorf("unsupported FREQ: %s", value)
+			}
+		case "INTERVAL":
+			r.Interval, err = strconv.Atoi(value)
+			if err != nil || r.Interval < 1 {
+				return RRule{}, fmt.Errorf("invalid INTERVAL: %s", value)
+			}
+		case "COUNT":
+			r.Count, err = strconv.Atoi(value)
+			if err != nil || r.Count < 1 {
+				return RRule{}, fmt.Errorf("invalid COUNT: %s", value)
+			}
+		case "UNTIL":
+			if r.Until, err = parseUntil(value); err != nil {
+				return RRule{}, err
+			}
+		case "BYDAY":
+			if r.ByDay, err = parseByDay(value); err != nil {
+				return RRule{}, err
+			}
+		case "BYMONTHDAY":
+			if r.ByMonthDay, err = parseIntList(value, -31, 31); err != nil {
+				return RRule{}, fmt.Errorf("invalid BYMONTHDAY: %w", err)
+			}
+		case "BYMONTH":
+			if r.ByMonth, err = parseIntList(value, 1, 12); err != nil {
+				return RRule{}, fmt.Errorf("invalid BYMONTH: %w", err)
+			}
+		case "BYSETPOS":
+			if r.BySetPos, err = parseIntList(value, -366, 366); err != nil {
+				return RRule{}, fmt.Errorf("invalid BYSETPOS: %w", err)
+			}
+		case "WKST":
+			if value != "MO" {
+				return RRule{}, fmt.Errorf("unsupported WKST: %s", value)
+			}
+		default:
+			return RRule{}, fmt.Errorf("unsupported rrule part: %s", name)
+		}
+	}
+
+	if r.Freq == "" {
+		return RRule{}, errors.New("rrule FREQ is required")
+	}
+	if r.Count > 0 && !r.Until.IsZero() {
+		return RRule{}, errors.New("rrule COUNT and UNTIL are mutually exclusive")
+	}
+	if r.Freq == Daily || r.Freq == Weekly {
+		for _, wd := range r.ByDay {
+			if wd.N != 0 {
+				return RRule{}, fmt.Errorf("BYDAY ordinals are not allowed with FREQ=%s", r.Freq)
+			}
+		}
+	}
+	// RFC 5545 does not allow BYMONTHDAY in weekly rules.
+	if r.Freq == Weekly && len(r.ByMonthDay) > 0 {
+		return RRule{}, errors.New("BYMONTHDAY is not allowed with FREQ=WEEKLY")
+	}
+	if len(r.BySetPos) > 0 && len(r.ByDay) == 0 && len(r.ByMonthDay) == 0 && len(r.ByMonth) == 0 {
+		return RRule{}, errors.New("BYSETPOS requires another BYxxx part")
+	}
+
+	return r, nil
+}
+
+func parseUntil(value string) (time.Time, error) {
+	for _, layout := range []string{"20060102T150405Z", "20060102T150405", "20060102"} {
+		if t, err := time.Parse(layout, value); err == nil {
+			return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC), nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid UNTIL: %s", value)
+}
+
+func parseByDay(value string) ([]WeekdayNum, error) {
+	var result []WeekdayNum
+	for _, item := range strings.Split(value, ",") {
+		if len(item) < 2 {
+			return nil, fmt.Errorf("invalid BYDAY: %s", item)
+		}
+		day, ok := rruleWeekdays[item[len(item)-2:]]
+		if !ok {
+			return nil, fmt.Errorf("invalid BYDAY: %s", item)
+		}
+		var n int
+		if ord := item[:len(item)-2]; ord != "" {
+			var err error
+			n, err = strconv.Atoi(ord)
+			if err != nil || n == 0 || n < -53 || n > 53 {
+				return nil, fmt.Errorf("invalid BYDAY: %s", item)
+			}
+		}
+		result = append(result, WeekdayNum{N: n, Day: day})
+	}
+	return result, nil
+}
+
+func parseIntList(value string, min, max int) ([]int, error) {
+	var result []int
+	for _, item := range strings.Split(value, ",") {
+		n, err := strconv.Atoi(item)
+		if err != nil {
+			return nil, err
+		}
+		if n == 0 || n < min || n > max {
+			return nil, fmt.Errorf("value out of range: %d", n)
+		}
+		result = append(result, n)
+	}
+	return result, nil
+}
+
+func (r RRule) String() string {
+	parts := []string{"FREQ=" + string(r.Freq)}
+	if r.Interval > 1 {
+		parts = append(parts, "INTERVAL="+strconv.Itoa(r.Interval))
+	}
+	if len(r.ByDay) > 0 {
+		days := make([]string, len(r.ByDay))
+		for i, wd := range r.ByDay {
+			days[i] = wd.String()
+		}
+		parts = append(parts, "BYDAY="+strings.Join(days, ","))
+	}
+	if len(r.ByMonthDay) > 0 {
+		parts = append(parts, "BYMONTHDAY="+joinInts(r.ByMonthDay))
+	}
+	if len(r.ByMonth) > 0 {
+		parts = append(parts, "BYMONTH="+joinInts(r.ByMonth))
+	}
+	if len(r.BySetPos) > 0 {
+		parts = append(parts, "BYSETPOS="+joinInts(r.BySetPos))
+	}
+	if r.Count > 0 {
+		parts = append(parts, "COUNT="+strconv.Itoa(r.Count))
+	}
+	if !r.Until.IsZero() {
+		parts = append(parts, "UNTIL="+r.Until.Format("20060102"))
+	}
+	return rrulePrefix + strings.Join(parts, ";")
+}
+
+func (wd WeekdayNum) String() string {
+	for name, day := range rruleWeekdays {
+		if day == wd.Day {
+			if wd.N != 0 {
+				return strconv.Itoa(wd.N) + name
+			}
+			return name
+		}
+	}
+	return ""
+}
+
+func joinInts(values []int) string {
+	parts := make([]string, len(values))
+	for i, v := range values {
+		parts[i] = strconv.Itoa(v)
+	}
+	return strings.Join(parts, ",")
+}
+
+// next returns the first occurrence of the rule started at start that is
+// after both start and now. COUNT is counted from start, which is always the
+// first occurrence.
+func (r RRule) next(start time.Time, now time.Time) (time.Time, error) {
+	after := now
+	if start.After(after) {
+		after = start
+	}
+	horizon := after.AddDate(maxRRuleYears, 0, 0)
+
+	count := 1
+	for period := 0; ; period++ {
+		periodStart, candidates := r.expand(start, period)
+		if periodStart.After(horizon) {
+			return time.Time{}, ErrRepeatFinished
+		}
+		for _, date := range candidates {
+			if !date.After(start) {
+				continue
+			}
+			if !r.Until.IsZero() && date.After(r.Until) {
+				return time.Time{}, ErrRepeatFinished
+			}
+			count++
+			if r.Count > 0 && count > r.Count {
+				return time.Time{}, ErrRepeatFinished
+			}
+			if date.After(after) {
+				return date, nil
+			}
+		}
+	}
+}
+
+// expand returns the first day of the n-th period of the rule and the sorted
+// occurrences that fall into it.
+func (r RRule) expand(start time.Time, n int) (time.Time, []time.Time) {
+	var periodStart time.Time
+	var dates []time.Time
+
+	switch r.Freq {
+	case Daily:
+		periodStart = start.AddDate(0, 0, n*r.Interval)
+		if r.matchMonth(periodStart) && r.matchMonthDay(periodStart) && r.matchWeekday(periodStart) {
+			dates = append(dates, periodStart)
+		}
+	case Weekly:
+		offset := (int(start.Weekday()) + 6) % 7
+		periodStart = start.AddDate(0, 0, n*7*r.Interval-offset)
+		for i := 0; i < 7; i++ {
+			date := periodStart.AddDate(0, 0, i)
+			if len(r.ByDay) == 0 && date.Weekday() != start.Weekday() {
+				continue
+			}
+			if r.matchWeekday(date) && r.matchMonth(date) {
+				dates = append(dates, date)
+			}
+		}
+	case Monthly:
+		periodStart = time.Date(start.Year(), start.Month()+time.Month(n*r.Interval), 1, 0, 0, 0, 0, time.UTC)
+		if r.matchMonth(periodStart) {
+			dates = r.monthDates(periodStart, start.Day())
+		}
+	case Yearly:
+		periodStart = time.Date(start.Year()+n*r.Interval, time.January, 1, 0, 0, 0, 0, time.UTC)
+		dates = r.yearDates(periodStart, start)
+	}
+
+	return periodStart, r.applySetPos(dates)
+}
+
+func (r RRule) monthDates(first time.Time, defaultDay int) []time.Time {
+	var dates []time.Time
+	last := first.AddDate(0, 1, -1).Day()
+	for day := 1; day <= last; day++ {
+		date := first.AddDate(0, 0, day-1)
+		if len(r.ByMonthDay) == 0 && len(r.ByDay) == 0 {
+			if day == defaultDay {
+				dates = append(dates, date)
+			}
+			continue
+		}
+		if !r.matchMonthDay(date) {
+			continue
+		}
+		if len(r.ByDay) > 0 && !matchNthWeekday(r.ByDay, date.Weekday(), (day-1)/7+1, (last-day)/7+1) {
+			continue
+		}
+		dates = append(dates, date)
+	}
+	return dates
+}
+
+func (r RRule) yearDates(first time.Time, start time.Time) []time.Time {
+	var dates []time.Time
+	if len(r.ByDay) > 0 && len(r.ByMonth) == 0 && len(r.ByMonthDay) == 0 {
+		last := first.AddDate(1, 0, -1).YearDay()
+		for day := 1; day <= last; day++ {
+			date := first.AddDate(0, 0, day-1)
+			if matchNthWeekday(r.ByDay, date.Weekday(), (day-1)/7+1, (last-day)/7+1) {
+				dates = append(dates, date)
+			}
+		}
+		return dates
+	}
+
+	months := r.ByMonth
+	if len(months) == 0 {
+		if len(r.ByMonthDay) > 0 || len(r.ByDay) > 0 {
+			months = []int{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12}
+		} else {
+			months = []int{int(start.Month())}
+		}
+	}
+	sorted := append([]int(nil), months...)
+	sort.Ints(sorted)
+	for _, month := range sorted {
+		dates = append(dates, r.monthDates(time.Date(first.Year(), time.Month(month), 1, 0, 0, 0, 0, time.UTC), start.Day())...)
+	}
+	return dates
+}
+
+func (r RRule) applySetPos(dates []time.Time) []time.Time {
+	if len(r.BySetPos) == 0 || len(dates) == 0 {
+		return dates
+	}
+	picked := make(map[int]bool)
+	for _, pos := range r.BySetPos {
+		idx := pos - 1
+		if pos < 0 {
+			idx = len(dates) + pos
+		}
+		if idx >= 0 && idx < len(dates) {
+			picked[idx] = true
+		}
+	}
+	var result []time.Time
+	for i, date := range dates {
+		if picked[i] {
+			result = append(result, date)
+		}
+	}
+	return result
+}
+
+func (r RRule) matchMonth(date time.Time) bool {
+	if len(r.ByMonth) == 0 {
+		return true
+	}
+	for _, month := range r.ByMonth {
+		if time.Month(month) == date.Month() {
+			return true
+		}
+	}
+	return false
+}
+
+func (r RRule) matchMonthDay(date time.Time) bool {
+	if len(r.ByMonthDay) == 0 {
+		return true
+	}
+	last := time.Date(date.Year(), date.Month()+1, 0, 0, 0, 0, 0, time.UTC).Day()
+	for _, day := range r.ByMonthDay {
+		if day == date.Day() || (day < 0 && last+day+1 == date.Day()) {
+			return true
+		}
+	}
+	return false
+}
+
+func (r RRule) matchWeekday(date time.Time) bool {
+	if len(r.ByDay) == 0 {
+		return true
+	}
+	for _, wd := range r.ByDay {
+		if wd.Day == date.Weekday() {
+			return true
+		}
+	}
+	return false
+}
+
+// matchNthWeekday reports whether a day with the given weekday, which is the
+// fromStart-th such weekday counted from the beginning of its period and the
+// fromEnd-th counted from the end, matches any of the BYDAY entries.
+func matchNthWeekday(byDay []WeekdayNum, weekday time.Weekday, fromStart int, fromEnd int) bool {
+	for _, wd := range byDay {
+		if wd.Day != weekday {
+			continue
+		}
+		if wd.N == 0 || wd.N == fromStart || -wd.N == fromEnd {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,63 @@
+package tests
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAgenda(t *testing.T) {
+	repeated := addTask(t, task{
+		date:   "21000301",
+		title:  "Полить цветы",
+		repeat: "d 2",
+	})
+	once := addTask(t, task{
+		date:  "21000302",
+		title: "Купить лейку",
+	})
+	defer func() {
+		for _, id := range []string{repeated, once} {
+			_, err := postJSON("api/task?id="+id, nil, http.MethodDelete)
+			assert.NoError(t, err)
+		}
+	}()
+
+	body, err := requestJSON("api/agenda?from=21000301&to=21000305", nil, http.MethodGet)
+	assert.NoError(t, err)
+	var resp struct {
+		Days []struct {
+			Date  string `json:"date"`
+			Tasks []struct {
+				ID string `json:"id"`
+			} `json:"tasks"`
+		} `json:"days"`
+	}
+	assert.NoError(t, json.Unmarshal(body, &resp))
+
+	got := make(map[string][]string)
+	var dates []string
+	for _, day := range resp.Days {
+		dates = append(dates, day.Date)
+		for _, task := range day.Tasks {
+			if task.ID == repeated || task.ID == once {
+				got[day.Date] = append(got[day.Date], task.ID)
+			}
+		}
+	}
+	assert.IsIncreasing(t, dates)
+	assert.Equal(t, map[string][]string{
+		"21000301": {repeated},
+		"21000302": {once},
+		"21000303": {repeated},
+		"21000305": {repeated},
+	}, got)
+
+	for _, query := range []string{"from=21000301&to=21000201", "from=2100", "from=21000101&to=21020101"} {
+		m, err := postJSON("api/agenda?"+query, nil, http.MethodGet)
+		assert.NoError(t, err)
+		assert.NotEmpty(t, m["error"], query)
+	}
+}
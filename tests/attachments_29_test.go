@@ -0,0 +1,161 @@
+package tests
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/textproto"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// sendAs sends a request with the test token and returns the response with
+// its body read.
+func sendAs(t *testing.T, method string, apipath string, contentType string, body io.Reader) (*http.Response, []byte) {
+	t.Helper()
+	req, err := http.NewRequest(method, getURL(apipath), body)
+	require.NoError(t, err)
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	if len(Token) > 0 {
+		req.AddCookie(&http.Cookie{Name: "token", Value: Token})
+	}
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	data, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	return resp, data
+}
+
+// upload attaches a file to a task and returns the decoded response.
+func upload(t *testing.T, task string, name string, contentType string, content []byte) map[string]any {
+	t.Helper()
+	var body bytes.Buffer
+	w := multipart.NewWriter(&body)
+	header := make(textproto.MIMEHeader)
+	header.Set("Content-Disposition", fmt.Sprintf(`form-data; name="file"; filename="%s"`, name))
+	header.Set("Content-Type", contentType)
+	part, err := w.CreatePart(header)
+	require.NoError(t, err)
+	_, err = part.Write(content)
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+
+	_, data := sendAs(t, http.MethodPost, "api/task/attachments?id="+task, w.FormDataContentType(), &body)
+	var m map[string]any
+	require.NoError(t, json.Unmarshal(data, &m), string(data))
+	return m
+}
+
+// attachmentNames returns the names of the attachments of a task.
+func attachmentNames(t *testing.T, task string) []string {
+	t.Helper()
+	ret, err := postJSON("api/task/attachments?id="+task, nil, http.MethodGet)
+	require.NoError(t, err)
+	list, ok := ret["attachments"].([]any)
+	require.True(t, ok, ret)
+	names := []string{}
+	for _, item := range list {
+		names = append(names, item.(map[string]any)["name"].(string))
+	}
+	return names
+}
+
+func TestAttachments(t *testing.T) {
+	task := addTask(t, task{date: "21000101", title: "Подать декларацию"})
+	defer postJSON("api/trash?id="+task, nil, http.MethodDelete)
+	defer postJSON("api/task?id="+task, nil, http.MethodDelete)
+
+	assert.Empty(t, attachmentNames(t, task))
+	content := []byte("%PDF-1.4 декларация")
+	ret := upload(t, task, "декларация.pdf", "application/pdf", content)
+	require.NotNil(t, ret["id"], ret)
+	declaration := jsonNumber(ret["id"])
+	ret = upload(t, task, "чек.txt", "text/plain", []byte("чек"))
+	require.NotNil(t, ret["id"], ret)
+	receipt := jsonNumber(ret["id"])
+	assert.Equal(t, []string{"декларация.pdf", "чек.txt"}, attachmentNames(t, task))
+	ret, err := postJSON("api/task/attachments?id="+task, nil, http.MethodGet)
+	require.NoError(t, err)
+	first := ret["attachments"].([]any)[0].(map[string]any)
+	assert.EqualValues(t, len(content), first["size"])
+	assert.Equal(t, "application/pdf", first["content_type"])
+	assert.NotContains(t, first, "file")
+
+	ret = upload(t, "999999999", "чек.txt", "text/plain", []byte("чек"))
+	assert.NotEmpty(t, ret["error"])
+	resp, data := sendAs(t, http.MethodPost, "api/task/attachments?id="+task, "application/json", nil)
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode, string(data))
+	ret, err = postJSON("api/task/attachments?id=999999999", nil, http.MethodGet)
+	assert.NoError(t, err)
+	assert.NotEmpty(t, ret["error"])
+
+	resp, data = sendAs(t, http.MethodGet, "api/task/attachments/file?attachment="+declaration, "", nil)
+	require.Equal(t, http.StatusOK, resp.StatusCode, string(data))
+	assert.Equal(t, content, data)
+	assert.Equal(t, "application/pdf", resp.Header.Get("Content-Type"))
+	assert.Contains(t, resp.Header.Get("Content-Disposition"), "attachment")
+	assert.Equal(t, "nosniff", resp.Header.Get("X-Content-Type-Options"))
+
+	// Only safe types are sent as uploaded.
+	for _, v := range []struct{ name, contentType, want string }{
+		{"страница.html", "text/html", "application/octet-stream"},
+		{"рисунок.svg", "image/svg+xml", "application/octet-stream"},
+		{"заметка.txt", "text/plain; charset=utf-8", "text/plain"},
+		{"фото.png", "IMAGE/PNG", "image/png"},
+	} {
+		ret = upload(t, task, v.name, v.contentType, []byte("<script>alert(1)</script>"))
+		require.NotNil(t, ret["id"], ret)
+		attachment := jsonNumber(ret["id"])
+		resp, data = sendAs(t, http.MethodGet, "api/task/attachments/file?attachment="+attachment, "", nil)
+		require.Equal(t, http.StatusOK, resp.StatusCode, string(data))
+		assert.Equal(t, v.want, resp.Header.Get("Content-Type"), v.contentType)
+		assert.Equal(t, "nosniff", resp.Header.Get("X-Content-Type-Options"))
+		ret, err = postJSON("api/task/attachments?attachment="+attachment, nil, http.MethodDelete)
+		assert.NoError(t, err)
+		assert.Empty(t, ret)
+	}
+
+	ret, err = postJSON("api/task/attachments?attachment="+receipt, nil, http.MethodDelete)
+	assert.NoError(t, err)
+	assert.Empty(t, ret)
+	assert.Equal(t, []string{"декларация.pdf"}, attachmentNames(t, task))
+	resp, _ = sendAs(t, http.MethodGet, "api/task/attachments/file?attachment="+receipt, "", nil)
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	ret, err = postJSON("api/task/attachments?attachment="+receipt, nil, http.MethodDelete)
+	assert.NoError(t, err)
+	assert.NotEmpty(t, ret["error"])
+
+	// Attachments of a task in the trash are out of reach until it is
+	// restored.
+	ret, err = postJSON("api/task?id="+task, nil, http.MethodDelete)
+	require.NoError(t, err)
+	require.Empty(t, ret)
+	resp, _ = sendAs(t, http.MethodGet, "api/task/attachments/file?attachment="+declaration, "", nil)
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	ret = upload(t, task, "чек.txt", "text/plain", []byte("чек"))
+	assert.NotEmpty(t, ret["error"])
+	ret, err = postJSON("api/trash/restore?id="+task, nil, http.MethodPost)
+	require.NoError(t, err)
+	require.Empty(t, ret)
+	resp, data = sendAs(t, http.MethodGet, "api/task/attachments/file?attachment="+declaration, "", nil)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, content, data)
+
+	// Purging the task deletes its attachments.
+	ret, err = postJSON("api/task?id="+task, nil, http.MethodDelete)
+	require.NoError(t, err)
+	require.Empty(t, ret)
+	ret, err = postJSON("api/trash?id="+task, nil, http.MethodDelete)
+	require.NoError(t, err)
+	require.Empty(t, ret)
+	resp, _ = sendAs(t, http.MethodGet, "api/task/attachments/file?attachment="+declaration, "", nil)
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+}
@@ -0,0 +1,95 @@
+package tests
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// checklist returns the items of a task's checklist as "text" or "+text" for
+// checked ones, and their ids.
+func checklist(t *testing.T, task string) ([]string, []string) {
+	t.Helper()
+	ret, err := postJSON("api/task/checklist?id="+task, nil, http.MethodGet)
+	require.NoError(t, err)
+	items, ok := ret["items"].([]any)
+	require.True(t, ok, ret)
+	texts, ids := []string{}, []string{}
+	for _, item := range items {
+		item := item.(map[string]any)
+		text := item["text"].(string)
+		if item["done"] == true {
+			text = "+" + text
+		}
+		texts = append(texts, text)
+		ids = append(ids, fmt.Sprint(item["id"]))
+	}
+	return texts, ids
+}
+
+func TestChecklists(t *testing.T) {
+	task := addTask(t, task{date: "21000101", title: "Собраться в отпуск", repeat: "y"})
+	defer postJSON("api/task?id="+task, nil, http.MethodDelete)
+
+	texts, _ := checklist(t, task)
+	assert.Empty(t, texts)
+	for _, text := range []string{"Паспорт", " Билеты ", "Зарядка"} {
+		ret, err := postJSON("api/task/checklist?id="+task, map[string]any{"text": text}, http.MethodPost)
+		require.NoError(t, err)
+		require.NotNil(t, ret["id"], ret)
+	}
+	for _, text := range []string{"", "   ", strings.Repeat("я", 257)} {
+		ret, err := postJSON("api/task/checklist?id="+task, map[string]any{"text": text}, http.MethodPost)
+		assert.NoError(t, err)
+		assert.NotEmpty(t, ret["error"])
+	}
+	ret, err := postJSON("api/task/checklist?id=999999999", map[string]any{"text": "Паспорт"}, http.MethodPost)
+	assert.NoError(t, err)
+	assert.NotEmpty(t, ret["error"])
+
+	texts, ids := checklist(t, task)
+	require.Equal(t, []string{"Паспорт", "Билеты", "Зарядка"}, texts)
+
+	ret, err = postJSON("api/task/checklist?id="+task, map[string]any{"order": []string{ids[1], ids[2], ids[0]}}, http.MethodPut)
+	assert.NoError(t, err)
+	assert.Empty(t, ret)
+	ret, err = postJSON("api/task/checklist?id="+task, map[string]any{"order": []string{ids[1], ids[2]}}, http.MethodPut)
+	assert.NoError(t, err)
+	assert.NotEmpty(t, ret["error"])
+
+	ret, err = postJSON("api/task/checklist/toggle?item="+ids[0], nil, http.MethodPost)
+	assert.NoError(t, err)
+	assert.Equal(t, true, ret["done"])
+	ret, err = postJSON("api/task/checklist/toggle?item="+ids[1], nil, http.MethodPost)
+	assert.NoError(t, err)
+	assert.Equal(t, true, ret["done"])
+	ret, err = postJSON("api/task/checklist/toggle?item="+ids[1], nil, http.MethodPost)
+	assert.NoError(t, err)
+	assert.Equal(t, false, ret["done"])
+	texts, _ = checklist(t, task)
+	assert.Equal(t, []string{"Билеты", "Зарядка", "+Паспорт"}, texts)
+
+	// Completing a repeating task moves it to the next year with the
+	// checklist unchecked.
+	ret, err = postJSON("api/task/done?id="+task, nil, http.MethodPost)
+	assert.NoError(t, err)
+	assert.Empty(t, ret)
+	texts, _ = checklist(t, task)
+	assert.Equal(t, []string{"Билеты", "Зарядка", "Паспорт"}, texts)
+
+	ret, err = postJSON("api/task/checklist?item="+ids[2], nil, http.MethodDelete)
+	assert.NoError(t, err)
+	assert.Empty(t, ret)
+	ret, err = postJSON("api/task/checklist?item="+ids[2], nil, http.MethodDelete)
+	assert.NoError(t, err)
+	assert.NotEmpty(t, ret["error"])
+	ret, err = postJSON("api/task/checklist/toggle?item="+ids[2], nil, http.MethodPost)
+	assert.NoError(t, err)
+	assert.NotEmpty(t, ret["error"])
+	texts, _ = checklist(t, task)
+	assert.Equal(t, []string{"Билеты", "Паспорт"}, texts)
+}
@@ -0,0 +1,96 @@
+package tests
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func getCompletions(t *testing.T, apipath string) []map[string]any {
+	t.Helper()
+	ret, err := postJSON(apipath, nil, http.MethodGet)
+	assert.NoError(t, err)
+	assert.Empty(t, ret["error"])
+	list, _ := ret["completions"].([]any)
+	result := make([]map[string]any, 0, len(list))
+	for _, item := range list {
+		result = append(result, item.(map[string]any))
+	}
+	return result
+}
+
+func TestCompletions(t *testing.T) {
+	db := openDB(t)
+	defer db.Close()
+
+	now := time.Now()
+	today := now.Format(`20060102`)
+	tomorrow := now.AddDate(0, 0, 1).Format(`20060102`)
+
+	ret, err := postJSON("api/task", map[string]any{
+		"date":  "21000101",
+		"title": "Отчёт",
+	}, http.MethodPost)
+	assert.NoError(t, err)
+	once := fmt.Sprint(ret["id"])
+
+	ret, err = postJSON("api/task/done?id="+once, map[string]any{"note": "отправлен"}, http.MethodPost)
+	assert.NoError(t, err)
+	assert.Empty(t, ret)
+	notFoundTask(t, once)
+
+	history := getCompletions(t, "api/task/history?id="+once)
+	assert.Len(t, history, 1)
+	if len(history) == 1 {
+		assert.Equal(t, once, history[0]["task_id"])
+		assert.Equal(t, "Отчёт", history[0]["title"])
+		assert.Equal(t, "21000101", history[0]["date"])
+		assert.Equal(t, "отправлен", history[0]["note"])
+		assert.NotEmpty(t, history[0]["completed_at"])
+	}
+
+	ret, err = postJSON("api/task", map[string]any{
+		"date":   today,
+		"title":  "Зарядка",
+		"repeat": "d 1",
+	}, http.MethodPost)
+	assert.NoError(t, err)
+	daily := fmt.Sprint(ret["id"])
+	for i := 0; i < 2; i++ {
+		ret, err = postJSON("api/task/done?id="+daily, nil, http.MethodPost)
+		assert.NoError(t, err)
+		assert.Empty(t, ret)
+	}
+
+	history = getCompletions(t, "api/task/history?id="+daily)
+	assert.Len(t, history, 2)
+	if len(history) == 2 {
+		assert.Equal(t, today, history[0]["date"])
+		assert.Equal(t, tomorrow, history[1]["date"])
+		assert.Nil(t, history[0]["note"])
+	}
+
+	found := map[string]int{}
+	for _, completion := range getCompletions(t, "api/completions?from="+today+"&to="+today) {
+		found[fmt.Sprint(completion["task_id"])]++
+	}
+	assert.Equal(t, 1, found[once])
+	assert.Equal(t, 2, found[daily])
+
+	after := now.AddDate(0, 0, 2).Format(`20060102`)
+	for _, completion := range getCompletions(t, "api/completions?from="+after) {
+		assert.NotEqual(t, daily, completion["task_id"])
+	}
+
+	ret, err = postJSON("api/completions?from=2024-01-01", nil, http.MethodGet)
+	assert.NoError(t, err)
+	assert.NotEmpty(t, ret["error"])
+
+	assert.Empty(t, getCompletions(t, "api/task/history?id=999999"))
+
+	_, err = postJSON("api/task?id="+daily, nil, http.MethodDelete)
+	assert.NoError(t, err)
+}
@@ -0,0 +1,101 @@
+package tests
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type taskPage struct {
+	Tasks []struct {
+		ID    string `json:"id"`
+		Title string `json:"title"`
+	} `json:"tasks"`
+	NextCursor string `json:"next_cursor"`
+	Total      *int   `json:"total"`
+}
+
+func getPage(t *testing.T, query string) taskPage {
+	body, err := requestJSON("api/tasks?"+query, nil, http.MethodGet)
+	assert.NoError(t, err)
+
+	var page taskPage
+	err = json.Unmarshal(body, &page)
+	assert.NoError(t, err)
+	return page
+}
+
+func TestCursor(t *testing.T) {
+	var ids []string
+	for _, date := range []string{"23000101", "23000102", "23000103", "23000104", "23000105"} {
+		ids = append(ids, addTask(t, task{date: date, title: "Задача шмякуса " + date}))
+	}
+	defer func() {
+		for _, id := range ids {
+			postJSON("api/task?id="+id, nil, http.MethodDelete)
+		}
+	}()
+
+	period := "from=23000101&to=23000131&limit=2"
+	page := getPage(t, period+"&cursor=")
+	assert.Len(t, page.Tasks, 2)
+	if assert.NotNil(t, page.Total) {
+		assert.Equal(t, 5, *page.Total)
+	}
+	assert.NotEmpty(t, page.NextCursor)
+	walked := []string{page.Tasks[0].ID, page.Tasks[1].ID}
+
+	// Completing a task on a page already seen doesn't shift the next pages.
+	ret, err := postJSON("api/task/done?id="+ids[0], nil, http.MethodPost)
+	assert.NoError(t, err)
+	assert.Empty(t, ret)
+	for page.NextCursor != "" && len(walked) < 10 {
+		page = getPage(t, period+"&cursor="+url.QueryEscape(page.NextCursor))
+		for _, task := range page.Tasks {
+			walked = append(walked, task.ID)
+		}
+	}
+	assert.Equal(t, ids, walked)
+	if assert.NotNil(t, page.Total) {
+		assert.Equal(t, 4, *page.Total)
+	}
+
+	var found []string
+	cursor := ""
+	for len(found) < 10 {
+		page = getPage(t, "search=шмякуса&limit=3&cursor="+url.QueryEscape(cursor))
+		for _, task := range page.Tasks {
+			found = append(found, task.ID)
+		}
+		if cursor = page.NextCursor; cursor == "" {
+			break
+		}
+	}
+	assert.ElementsMatch(t, ids[1:], found)
+
+	// Empty pages and the search by date are written the same way.
+	for _, query := range []string{"from=29990101&cursor=", "search=29.12.2999&cursor="} {
+		ret, err := postJSON("api/tasks?"+query, nil, http.MethodGet)
+		assert.NoError(t, err)
+		assert.Equal(t, []any{}, ret["tasks"], query)
+		assert.EqualValues(t, 0, ret["total"], query)
+	}
+	page = getPage(t, "search=03.01.2300&cursor=")
+	if assert.Len(t, page.Tasks, 1) {
+		assert.Equal(t, ids[2], page.Tasks[0].ID)
+	}
+	if assert.NotNil(t, page.Total) {
+		assert.Equal(t, 1, *page.Total)
+	}
+
+	assert.Nil(t, getPage(t, period).Total)
+	for _, query := range []string{"cursor=bm90IGEgY3Vyc29y", "cursor=%25%25", "cursor=&offset=2",
+		"sort=title&cursor=" + url.QueryEscape(getPage(t, period+"&cursor=").NextCursor)} {
+		ret, err := postJSON("api/tasks?"+query, nil, http.MethodGet)
+		assert.NoError(t, err)
+		assert.NotEmpty(t, ret["error"], "Ожидается ошибка для %q", query)
+	}
+}
@@ -11,11 +11,19 @@ import (
 )
 
 type Task struct {
-	ID      int64  `db:"id"`
-	Date    string `db:"date"`
-	Title   string `db:"title"`
-	Comment string `db:"comment"`
-	Repeat  string `db:"repeat"`
+	ID         int64  `db:"id"`
+	Date       string `db:"date"`
+	Time       string `db:"time"`
+	Title      string `db:"title"`
+	Comment    string `db:"comment"`
+	Repeat     string `db:"repeat"`
+	Occurrence int    `db:"occurrence"`
+	Shift      string `db:"shift"`
+	Anchor     string `db:"anchor"`
+	DeletedAt  string `db:"deleted_at"`
+	UserID     int64  `db:"user_id"`
+	ListID     int64  `db:"list_id"`
+	Priority   int    `db:"priority"`
 }
 
 func count(db *sqlx.DB) (int, error) {
@@ -0,0 +1,80 @@
+package tests
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDependencies(t *testing.T) {
+	const day = "21500601"
+	buy := addTask(t, task{date: day, title: "Купить плитку"})
+	lay := addTask(t, task{date: day, title: "Уложить плитку"})
+	defer func() {
+		for _, id := range []string{buy, lay} {
+			postJSON("api/task?id="+id, nil, http.MethodDelete)
+		}
+	}()
+
+	ret, err := postJSON("api/task/dependencies?id="+lay+"&blocker="+buy, nil, http.MethodPost)
+	require.NoError(t, err)
+	require.Empty(t, ret)
+	for _, params := range []string{"id=" + buy + "&blocker=" + lay, "id=" + lay + "&blocker=" + lay,
+		"id=" + lay + "&blocker=999999999", "id=" + lay} {
+		ret, err = postJSON("api/task/dependencies?"+params, nil, http.MethodPost)
+		assert.NoError(t, err)
+		assert.NotEmpty(t, ret["error"], "Ожидается ошибка для %q", params)
+	}
+
+	ret, err = postJSON("api/task?id="+lay, nil, http.MethodGet)
+	assert.NoError(t, err)
+	assert.Equal(t, true, ret["blocked"])
+	ret, err = postJSON("api/task?id="+buy, nil, http.MethodGet)
+	assert.NoError(t, err)
+	assert.Nil(t, ret["blocked"])
+	ret, err = postJSON("api/tasks?from="+day+"&to="+day, nil, http.MethodGet)
+	assert.NoError(t, err)
+	list, _ := ret["tasks"].([]any)
+	require.Len(t, list, 2)
+	assert.Equal(t, lay, list[1].(map[string]any)["id"])
+	assert.Equal(t, true, list[1].(map[string]any)["blocked"])
+
+	ret, err = postJSON("api/task/dependencies?id="+lay, nil, http.MethodGet)
+	assert.NoError(t, err)
+	blockers, _ := ret["blockers"].([]any)
+	require.Len(t, blockers, 1)
+	assert.Equal(t, buy, blockers[0].(map[string]any)["id"])
+
+	ret, err = postJSON("api/task/done?id="+lay, nil, http.MethodPost)
+	assert.NoError(t, err)
+	assert.NotEmpty(t, ret["error"])
+
+	// Deleting the blocker unblocks the task, restoring it blocks it again.
+	ret, err = postJSON("api/task?id="+buy, nil, http.MethodDelete)
+	assert.NoError(t, err)
+	assert.Empty(t, ret)
+	ret, err = postJSON("api/task?id="+lay, nil, http.MethodGet)
+	assert.NoError(t, err)
+	assert.Nil(t, ret["blocked"])
+	ret, err = postJSON("api/trash/restore?id="+buy, nil, http.MethodPost)
+	assert.NoError(t, err)
+	assert.Empty(t, ret)
+	ret, err = postJSON("api/task?id="+lay, nil, http.MethodGet)
+	assert.NoError(t, err)
+	assert.Equal(t, true, ret["blocked"])
+
+	ret, err = postJSON("api/task/dependencies?id="+lay+"&blocker="+buy, nil, http.MethodDelete)
+	assert.NoError(t, err)
+	assert.Empty(t, ret)
+	ret, err = postJSON("api/task/dependencies?id="+lay+"&blocker="+buy, nil, http.MethodDelete)
+	assert.NoError(t, err)
+	assert.NotEmpty(t, ret["error"])
+	ret, err = postJSON("api/task/dependencies?id="+lay, nil, http.MethodGet)
+	assert.NoError(t, err)
+	assert.Equal(t, []any{}, ret["blockers"])
+	ret, err = postJSON("api/task/done?id="+lay, nil, http.MethodPost)
+	assert.NoError(t, err)
+	assert.Empty(t, ret)
+}
@@ -0,0 +1,37 @@
+package tests
+
+import (
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSearchFilters(t *testing.T) {
+	ids := []string{
+		addTask(t, task{date: "21001015", title: "Отчёт по зюзябре", comment: "квартальный"}),
+		addTask(t, task{date: "21001020", title: "Планёрка по зюзябре", comment: "еженедельная", repeat: "d 7"}),
+		addTask(t, task{date: "21001105", title: "Звонок по зюзябре"}),
+	}
+	defer func() {
+		for _, id := range ids {
+			_, err := postJSON("api/task?id="+id, nil, http.MethodDelete)
+			assert.NoError(t, err)
+		}
+	}()
+
+	assert.ElementsMatch(t, []string{"Отчёт по зюзябре", "Планёрка по зюзябре"},
+		titles(searchTasks(t, "зюзябре after:21001001 before:21001101")))
+	assert.Equal(t, []string{"Звонок по зюзябре"}, titles(searchTasks(t, "зюзябре after:01.11.2100")))
+	assert.Equal(t, []string{"Планёрка по зюзябре"}, titles(searchTasks(t, "зюзябре repeat:yes")))
+	assert.ElementsMatch(t, []string{"Отчёт по зюзябре", "Звонок по зюзябре"}, titles(searchTasks(t, "зюзябре repeat:no")))
+	assert.ElementsMatch(t, []string{"Планёрка по зюзябре", "Звонок по зюзябре"}, titles(searchTasks(t, "зюзябре -квартальный")))
+	assert.Equal(t, []string{"Звонок по зюзябре"}, titles(searchTasks(t, `зюзябре -квартальный -"еженедельная"`)))
+
+	for _, search := range []string{"before:20261301", "after:", "repeat:maybe", "tag:", "tag:a,b"} {
+		ret, err := postJSON("api/tasks?search="+url.QueryEscape(search), nil, http.MethodGet)
+		assert.NoError(t, err)
+		assert.NotEmpty(t, ret["error"], "Ожидается ошибка для поиска %q", search)
+	}
+}
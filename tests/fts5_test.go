@@ -0,0 +1,16 @@
+//go:build !sqlite_fts5
+
+package tests
+
+import (
+	"fmt"
+	"os"
+	"testing"
+)
+
+// TestMain stops builds without FTS5 early: the tests write to the server's
+// SQLite database, whose full-text index triggers need it.
+func TestMain(m *testing.M) {
+	fmt.Fprintln(os.Stderr, "SQLite is built without FTS5, run the tests with -tags sqlite_fts5")
+	os.Exit(1)
+}
@@ -0,0 +1,121 @@
+package tests
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHolidays(t *testing.T) {
+	db := openDB(t)
+	defer db.Close()
+
+	ret, err := postJSON("api/holidays", map[string]any{"date": "2100-03-01"}, http.MethodPost)
+	assert.NoError(t, err)
+	assert.NotEmpty(t, ret["error"])
+
+	ret, err = postJSON("api/holidays", map[string]any{"date": "21000301", "name": "Праздник"}, http.MethodPost)
+	assert.NoError(t, err)
+	assert.Empty(t, ret)
+
+	tbl := []struct {
+		now   string
+		shift string
+		want  string
+	}{
+		{"21000101", "next", "21000201"},
+		{"21000201", "next", "21000302"},
+		{"21000201", "prev", "21000226"},
+		{"21000401", "next", "21000503"},
+		{"21000401", "prev", "21000430"},
+		{"21000401", "", "21000501"},
+		{"21000401", "up", ""},
+	}
+	for _, v := range tbl {
+		urlPath := fmt.Sprintf("api/nextdate?now=%s&date=21000101&repeat=%s&shift=%s",
+			v.now, url.QueryEscape("m 1"), v.shift)
+		get, err := getBody(urlPath)
+		assert.NoError(t, err)
+		next := strings.TrimSpace(string(get))
+		if v.want == "" {
+			assert.Contains(t, next, "error", "%v", v)
+			continue
+		}
+		assert.Equal(t, v.want, next, "%v", v)
+	}
+
+	ret, err = postJSON("api/task", map[string]any{
+		"date":   "21000501",
+		"title":  "Отчёт",
+		"repeat": "m 1",
+		"shift":  "next",
+	}, http.MethodPost)
+	assert.NoError(t, err)
+	id := fmt.Sprint(ret["id"])
+
+	var task Task
+	err = db.Get(&task, `SELECT * FROM scheduler WHERE id=?`, id)
+	assert.NoError(t, err)
+	assert.Equal(t, "21000503", task.Date)
+	assert.Equal(t, "21000501", task.Anchor)
+	assert.Equal(t, "next", task.Shift)
+
+	// Occurrences just outside a range that are shifted into it are shown,
+	// those shifted out of it are not.
+	assert.Equal(t, []string{"21000503"}, agendaDates(t, id, day(t, "21000503"), day(t, "21000509")))
+	assert.Empty(t, agendaDates(t, id, day(t, "21000425"), day(t, "21000502")))
+	assert.Equal(t, []string{"21000503", "21000601"}, occurrenceDates(t, "21000501", "m 1", "next", "21000503", "21000630"))
+	assert.Empty(t, occurrenceDates(t, "21000501", "m 1", "next", "21000501", "21000502"))
+	_, err = postJSON("api/task?id="+id, nil, http.MethodDelete)
+	assert.NoError(t, err)
+
+	ret, err = postJSON("api/task", map[string]any{
+		"date":   "21000801",
+		"title":  "Отчёт заранее",
+		"repeat": "m 1",
+		"shift":  "prev",
+	}, http.MethodPost)
+	assert.NoError(t, err)
+	id = fmt.Sprint(ret["id"])
+	assert.Equal(t, []string{"21000730"}, agendaDates(t, id, day(t, "21000724"), day(t, "21000730")))
+	assert.Empty(t, agendaDates(t, id, day(t, "21000731"), day(t, "21000806")))
+	assert.Equal(t, []string{"21000730"}, occurrenceDates(t, "21000801", "m 1", "prev", "21000724", "21000730"))
+	_, err = postJSON("api/task?id="+id, nil, http.MethodDelete)
+	assert.NoError(t, err)
+
+	ret, err = postJSON("api/holidays?date=21000301", nil, http.MethodDelete)
+	assert.NoError(t, err)
+	assert.Empty(t, ret)
+	ret, err = postJSON("api/holidays?date=21000301", nil, http.MethodDelete)
+	assert.NoError(t, err)
+	assert.NotEmpty(t, ret["error"])
+}
+
+// day parses a date in the 20060102 format.
+func day(t *testing.T, date string) time.Time {
+	t.Helper()
+	parsed, err := time.Parse("20060102", date)
+	require.NoError(t, err)
+	return parsed
+}
+
+// occurrenceDates returns the dates from /api/occurrences.
+func occurrenceDates(t *testing.T, date, repeat, shift, from, to string) []string {
+	t.Helper()
+	ret, err := postJSON(fmt.Sprintf("api/occurrences?date=%s&repeat=%s&shift=%s&from=%s&to=%s",
+		date, url.QueryEscape(repeat), shift, from, to), nil, http.MethodGet)
+	require.NoError(t, err)
+	list, ok := ret["dates"].([]any)
+	require.True(t, ok, ret)
+	dates := []string{}
+	for _, date := range list {
+		dates = append(dates, date.(string))
+	}
+	return dates
+}
@@ -0,0 +1,143 @@
+package tests
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// findList returns the list with the given id from /api/lists.
+func findList(t *testing.T, id string) map[string]any {
+	t.Helper()
+	ret, err := postJSON("api/lists", nil, http.MethodGet)
+	require.NoError(t, err)
+	list, _ := ret["lists"].([]any)
+	for _, item := range list {
+		if found := item.(map[string]any); found["id"] == id {
+			return found
+		}
+	}
+	return nil
+}
+
+func listTitles(t *testing.T, list string) []string {
+	t.Helper()
+	var result []string
+	ret, err := postJSON("api/tasks?list="+list, nil, http.MethodGet)
+	require.NoError(t, err)
+	items, _ := ret["tasks"].([]any)
+	for _, item := range items {
+		result = append(result, item.(map[string]any)["title"].(string))
+	}
+	return result
+}
+
+func TestLists(t *testing.T) {
+	name := fmt.Sprintf("Работа %d", time.Now().UnixNano())
+	ret, err := postJSON("api/lists", map[string]any{"name": name}, http.MethodPost)
+	require.NoError(t, err)
+	require.NotNil(t, ret["id"], ret)
+	id := fmt.Sprint(ret["id"])
+	defer postJSON("api/lists?id="+id, nil, http.MethodDelete)
+
+	ret, err = postJSON("api/lists", map[string]any{"name": name}, http.MethodPost)
+	assert.NoError(t, err)
+	assert.NotEmpty(t, ret["error"])
+	ret, err = postJSON("api/lists", map[string]any{"name": "  "}, http.MethodPost)
+	assert.NoError(t, err)
+	assert.NotEmpty(t, ret["error"])
+
+	ret, err = postJSON("api/task", map[string]any{"date": "21000101", "title": "Отчёт в списке", "list_id": id}, http.MethodPost)
+	require.NoError(t, err)
+	require.NotNil(t, ret["id"], ret)
+	inList := fmt.Sprint(ret["id"])
+	outside := addTask(t, task{date: "21000102", title: "Отчёт вне списка"})
+	ret, err = postJSON("api/task", map[string]any{"date": "21000101", "title": "Отчёт", "list_id": "999999999"}, http.MethodPost)
+	assert.NoError(t, err)
+	assert.NotEmpty(t, ret["error"])
+	// List ids may be given as numbers too.
+	number, err := strconv.ParseInt(id, 10, 64)
+	require.NoError(t, err)
+	ret, err = postJSON("api/task", map[string]any{"date": "21000101", "title": "Отчёт по номеру", "list_id": number}, http.MethodPost)
+	require.NoError(t, err)
+	require.NotNil(t, ret["id"], ret)
+	byNumber := fmt.Sprint(ret["id"])
+	ret, err = postJSON("api/task?id="+byNumber, nil, http.MethodGet)
+	assert.NoError(t, err)
+	assert.Equal(t, id, ret["list_id"])
+	ret, err = postJSON("api/task", map[string]any{"id": byNumber, "date": "21000101", "title": "Отчёт по номеру",
+		"list_id": number}, http.MethodPut)
+	assert.NoError(t, err)
+	assert.Empty(t, ret)
+	postJSON("api/task?id="+byNumber, nil, http.MethodDelete)
+
+	ret, err = postJSON("api/task?id="+inList, nil, http.MethodGet)
+	assert.NoError(t, err)
+	assert.Equal(t, id, ret["list_id"])
+	assert.Equal(t, []string{"Отчёт в списке"}, listTitles(t, id))
+	list := findList(t, id)
+	require.NotNil(t, list)
+	assert.Equal(t, name, list["name"])
+	assert.EqualValues(t, 1, list["tasks"])
+
+	ret, err = postJSON("api/task/move?id="+outside+"&list="+id, nil, http.MethodPost)
+	assert.NoError(t, err)
+	assert.Empty(t, ret)
+	ret, err = postJSON("api/task/move?id="+inList, nil, http.MethodPost)
+	assert.NoError(t, err)
+	assert.Empty(t, ret)
+	assert.Equal(t, []string{"Отчёт вне списка"}, listTitles(t, id))
+	ret, err = postJSON("api/task/move?id="+inList+"&list=999999999", nil, http.MethodPost)
+	assert.NoError(t, err)
+	assert.NotEmpty(t, ret["error"])
+
+	// Editing a task keeps it in its list.
+	ret, err = postJSON("api/task", map[string]any{"id": outside, "date": "21000103", "title": "Отчёт вне списка"}, http.MethodPut)
+	assert.NoError(t, err)
+	assert.Empty(t, ret)
+	assert.Equal(t, []string{"Отчёт вне списка"}, listTitles(t, id))
+	ret, err = postJSON("api/task?id="+outside, nil, http.MethodDelete)
+	assert.NoError(t, err)
+	assert.Empty(t, ret)
+	assert.EqualValues(t, 0, findList(t, id)["tasks"])
+	assert.Empty(t, listTitles(t, id))
+	ret, err = postJSON("api/trash/restore?id="+outside, nil, http.MethodPost)
+	assert.NoError(t, err)
+	assert.Empty(t, ret)
+
+	ret, err = postJSON("api/lists", map[string]any{"id": id, "name": name + " и дом"}, http.MethodPut)
+	assert.NoError(t, err)
+	assert.Empty(t, ret)
+	assert.Equal(t, name+" и дом", findList(t, id)["name"])
+	ret, err = postJSON("api/lists", map[string]any{"id": "999999999", "name": name}, http.MethodPut)
+	assert.NoError(t, err)
+	assert.NotEmpty(t, ret["error"])
+
+	ret, err = postJSON("api/tasks?list="+id+"&search=Отчёт", nil, http.MethodGet)
+	assert.NoError(t, err)
+	assert.NotEmpty(t, ret["error"])
+	ret, err = postJSON("api/tasks?list=abc", nil, http.MethodGet)
+	assert.NoError(t, err)
+	assert.NotEmpty(t, ret["error"])
+
+	ret, err = postJSON("api/lists?id="+id, nil, http.MethodDelete)
+	assert.NoError(t, err)
+	assert.Empty(t, ret)
+	assert.Nil(t, findList(t, id))
+	ret, err = postJSON("api/task?id="+outside, nil, http.MethodGet)
+	assert.NoError(t, err)
+	assert.Equal(t, "Отчёт вне списка", ret["title"])
+	assert.Nil(t, ret["list_id"])
+	ret, err = postJSON("api/lists?id="+id, nil, http.MethodDelete)
+	assert.NoError(t, err)
+	assert.NotEmpty(t, ret["error"])
+
+	for _, task := range []string{inList, outside} {
+		postJSON("api/task?id="+task, nil, http.MethodDelete)
+	}
+}
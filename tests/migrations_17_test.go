@@ -0,0 +1,156 @@
+package tests
+
+import (
+	"database/sql"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"main/core/database/postgres"
+	"main/core/database/sqlite"
+	"main/core/database/store"
+	"main/internal/models/holidays"
+	"main/internal/models/lists"
+	"main/internal/models/tasks"
+)
+
+func TestMigrations(t *testing.T) {
+	db := openDB(t)
+	defer db.Close()
+
+	var versions []int
+	err := db.Select(&versions, `SELECT version FROM schema_migrations ORDER BY version`)
+	assert.NoError(t, err)
+	assert.NotEmpty(t, versions)
+	for i, version := range versions {
+		assert.Equal(t, i+1, version)
+	}
+
+	var tables []string
+	err = db.Select(&tables, `SELECT name FROM sqlite_master WHERE type = 'table' ORDER BY name`)
+	assert.NoError(t, err)
+	for _, table := range []string{"scheduler", "holidays", "task_completions"} {
+		assert.Contains(t, tables, table)
+	}
+}
+
+// migrateSteps is how many migrations TestMigrateDownUp reverts. None of the
+// data it adds is kept in the tables they create.
+const migrateSteps = "3"
+
+// TestMigrateDownUp reverts the last migrations with the migrate subcommand
+// and applies them again, checking that the schema and the data come through.
+func TestMigrateDownUp(t *testing.T) {
+	api := filepath.Join(t.TempDir(), "api")
+	out, err := exec.Command("go", "build", "-tags", "sqlite_fts5", "-o", api, "../cmd/api").CombinedOutput()
+	require.NoError(t, err, string(out))
+
+	t.Run("sqlite", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "scheduler.db")
+		testMigrateDownUp(t, api, []string{"TODO_DB_DRIVER=sqlite", "TODO_DBFILE=" + path},
+			func() (store.Store, error) { return sqlite.New(path) },
+			func() (*sql.DB, error) { return sqlite.Open(path) },
+			`SELECT type || ' ' || name || ' ' || coalesce(sql, '') FROM sqlite_master
+			 WHERE name NOT LIKE 'sqlite_%' ORDER BY 1`)
+	})
+	t.Run("postgres", func(t *testing.T) {
+		dsn := os.Getenv("TODO_TEST_POSTGRES_DSN")
+		if dsn == "" {
+			t.Skip("TODO_TEST_POSTGRES_DSN is not set")
+		}
+		dsn = postgresSchema(t, dsn)
+		testMigrateDownUp(t, api, []string{"TODO_DB_DRIVER=postgres", "TODO_DB_DSN=" + dsn},
+			func() (store.Store, error) { return postgres.New(dsn) },
+			func() (*sql.DB, error) { return postgres.Open(dsn) },
+			`SELECT table_name || '.' || column_name || ' ' || data_type || ' ' ||
+			        is_nullable || ' ' || coalesce(column_default, '')
+			 FROM information_schema.columns WHERE table_schema = current_schema()
+			 UNION ALL
+			 SELECT indexdef FROM pg_indexes WHERE schemaname = current_schema()
+			 ORDER BY 1`)
+	})
+}
+
+// testMigrateDownUp fills a store opened with open, runs the migrate
+// subcommand of api with the environment env down and up again, and checks
+// the store is as it was. The schema is compared by the rows of the query
+// schema, run on a database opened with db.
+func testMigrateDownUp(t *testing.T, api string, env []string,
+	open func() (store.Store, error), db func() (*sql.DB, error), schema string) {
+	s, err := open()
+	require.NoError(t, err)
+	listID, err := s.AddList(lists.List{Name: "Работа"})
+	require.NoError(t, err)
+	weeklyID, err := s.AddTaskDB(tasks.Task{Date: "21000101", Title: "Planning meeting",
+		Repeat: "d 7", ListID: listID, Tags: []string{"встречи"}})
+	require.NoError(t, err)
+	require.NoError(t, s.DoneTask(fmt.Sprint(weeklyID), "перенесли"))
+	require.NoError(t, s.AddHoliday(holidays.Holiday{Date: "21000107", Name: "Рождество"}))
+	require.NoError(t, s.Close())
+
+	before := schemaRows(t, db, schema)
+
+	migrate := func(args ...string) string {
+		cmd := exec.Command(api, append([]string{"migrate"}, args...)...)
+		cmd.Env = append(os.Environ(), env...)
+		out, err := cmd.CombinedOutput()
+		require.NoError(t, err, string(out))
+		return string(out)
+	}
+	assert.Contains(t, migrate("down", migrateSteps), "reverted "+migrateSteps+" migrations")
+	assert.Equal(t, migrateSteps, fmt.Sprint(strings.Count(migrate("status"), "pending")))
+	assert.NotEqual(t, before, schemaRows(t, db, schema))
+	assert.Contains(t, migrate("up"), "applied "+migrateSteps+" migrations")
+	assert.NotContains(t, migrate("status"), "pending")
+	assert.Equal(t, before, schemaRows(t, db, schema))
+
+	s, err = open()
+	require.NoError(t, err)
+	defer s.Close()
+	task, err := s.FindTask(fmt.Sprint(weeklyID))
+	require.NoError(t, err)
+	assert.Equal(t, "Planning meeting", task.Title)
+	assert.Equal(t, "21000108", task.Date)
+	assert.Equal(t, listID, task.ListID)
+	assert.Equal(t, []string{"встречи"}, task.Tags)
+	history, err := s.TaskHistory(fmt.Sprint(weeklyID))
+	require.NoError(t, err)
+	if assert.Len(t, history, 1) {
+		assert.Equal(t, "перенесли", history[0].Note)
+	}
+	all, err := s.Holidays()
+	require.NoError(t, err)
+	assert.Equal(t, []holidays.Holiday{{Date: "21000107", Name: "Рождество"}}, all)
+
+	filter, err := store.ParseFilter("meeting")
+	require.NoError(t, err)
+	page, err := s.SearchTasks(filter, store.Page{})
+	require.NoError(t, err)
+	assert.Equal(t, []string{fmt.Sprint(weeklyID)}, ids(page.Tasks))
+}
+
+// schemaRows returns the rows of the query schema describing the schema of
+// the database opened with db.
+func schemaRows(t *testing.T, db func() (*sql.DB, error), schema string) []string {
+	t.Helper()
+	conn, err := db()
+	require.NoError(t, err)
+	defer conn.Close()
+	rows, err := conn.Query(schema)
+	require.NoError(t, err)
+	defer rows.Close()
+	var result []string
+	for rows.Next() {
+		var row string
+		require.NoError(t, rows.Scan(&row))
+		result = append(result, row)
+	}
+	require.NoError(t, rows.Err())
+	return result
+}
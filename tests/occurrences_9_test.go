@@ -0,0 +1,52 @@
+package tests
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type occurrences struct {
+	date   string
+	repeat string
+	from   string
+	to     string
+	limit  string
+	want   []string
+}
+
+func TestOccurrences(t *testing.T) {
+	tbl := []occurrences{
+		{"20240101", "k 1", "20240101", "", "", nil},
+		{"20240101", "d 7", "20240110", "20240101", "", nil},
+		{"20240101", "d 7", "20240110", "", "0", nil},
+		{"20240101", "d 7", "20240110", "", "3", []string{"20240115", "20240122", "20240129"}},
+		{"20240101", "", "20231201", "20241231", "", []string{"20240101"}},
+		{"20240101", "", "20240102", "20241231", "", []string{}},
+		{"20240125", "w 1,3", "20240101", "20240204", "", []string{"20240125", "20240129", "20240131"}},
+		{"20240101", "RRULE:FREQ=DAILY;COUNT=3", "20240102", "", "", []string{"20240102", "20240103"}},
+		{"20240101", "RRULE:FREQ=MONTHLY;BYDAY=-1FR", "20240201", "20240430", "",
+			[]string{"20240223", "20240329", "20240426"}},
+	}
+	for _, v := range tbl {
+		urlPath := fmt.Sprintf("api/occurrences?date=%s&repeat=%s&from=%s&to=%s&limit=%s",
+			v.date, url.QueryEscape(v.repeat), v.from, v.to, v.limit)
+		body, err := getBody(urlPath)
+		assert.NoError(t, err)
+
+		var resp struct {
+			Dates []string `json:"dates"`
+			Error string   `json:"error"`
+		}
+		assert.NoError(t, json.Unmarshal(body, &resp))
+		if v.want == nil {
+			assert.NotEmpty(t, resp.Error, "Ожидается ошибка для %v", v)
+			continue
+		}
+		assert.Empty(t, resp.Error, "Неожиданная ошибка для %v", v)
+		assert.Equal(t, v.want, resp.Dates, "%v", v)
+	}
+}
@@ -0,0 +1,78 @@
+package tests
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"main/core/database/sqlite"
+	"main/internal/models/tasks"
+)
+
+func TestOpenExistingDB(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "scheduler.db")
+	s, err := sqlite.New(path)
+	require.NoError(t, err)
+	id, err := s.AddTaskDB(tasks.Task{Date: "21000101", Title: "Пережить перезапуск"})
+	require.NoError(t, err)
+	require.NoError(t, s.Close())
+
+	s, err = sqlite.New(path)
+	require.NoError(t, err)
+	defer s.Close()
+	task, err := s.FindTask(strconv.FormatInt(id, 10))
+	require.NoError(t, err)
+	assert.Equal(t, "Пережить перезапуск", task.Title)
+}
+
+func TestOpenCorruptDB(t *testing.T) {
+	dir := t.TempDir()
+
+	garbage := filepath.Join(dir, "garbage.db")
+	require.NoError(t, os.WriteFile(garbage, []byte("это не база данных, а просто текст"), 0o644))
+	_, err := sqlite.New(garbage)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "cannot use database "+garbage)
+	assert.Contains(t, err.Error(), "database is corrupt")
+
+	// A file with a valid header and damaged pages.
+	damaged := filepath.Join(dir, "damaged.db")
+	s, err := sqlite.New(damaged)
+	require.NoError(t, err)
+	for i := 0; i < 50; i++ {
+		_, err = s.AddTaskDB(tasks.Task{Date: "21000101", Title: "Задача для порчи", Comment: "комментарий подлиннее"})
+		require.NoError(t, err)
+	}
+	require.NoError(t, s.Close())
+	data, err := os.ReadFile(damaged)
+	require.NoError(t, err)
+	require.Greater(t, len(data), 8192)
+	for i := 4096; i < len(data); i++ {
+		data[i] = 0xA5
+	}
+	require.NoError(t, os.WriteFile(damaged, data, 0o644))
+	_, err = sqlite.New(damaged)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "database is corrupt")
+}
+
+func TestOpenNewerDB(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "scheduler.db")
+	s, err := sqlite.New(path)
+	require.NoError(t, err)
+	require.NoError(t, s.Close())
+
+	db, err := sqlite.Open(path)
+	require.NoError(t, err)
+	_, err = db.Exec("INSERT INTO schema_migrations (version, name, applied_at) VALUES (9999, 'from_the_future', '')")
+	require.NoError(t, err)
+	require.NoError(t, db.Close())
+
+	_, err = sqlite.New(path)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "database schema version 9999 is newer than")
+}
@@ -0,0 +1,82 @@
+package tests
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPriorities(t *testing.T) {
+	const day = "21500303"
+
+	routine := addTask(t, task{date: day, title: "Полить цветы"})
+	ret, err := postJSON("api/task", map[string]any{"date": day, "time": "2000", "title": "Сдать декларацию",
+		"priority": "1"}, http.MethodPost)
+	require.NoError(t, err)
+	require.NotNil(t, ret["id"], ret)
+	urgent := fmt.Sprint(ret["id"])
+	defer func() {
+		for _, id := range []string{routine, urgent} {
+			postJSON("api/task?id="+id, nil, http.MethodDelete)
+		}
+	}()
+
+	for _, priority := range []any{"5", "-1", "high", 5, 1.5} {
+		ret, err = postJSON("api/task", map[string]any{"date": day, "title": "Задача", "priority": priority}, http.MethodPost)
+		assert.NoError(t, err)
+		assert.NotEmpty(t, ret["error"], "Ожидается ошибка для приоритета %v", priority)
+	}
+
+	ret, err = postJSON("api/task?id="+routine, nil, http.MethodGet)
+	assert.NoError(t, err)
+	assert.Equal(t, "4", ret["priority"])
+
+	// The urgent task comes first although it is later in the day.
+	assert.Equal(t, []string{"Сдать декларацию", "Полить цветы"}, taggedTitles(t, "from="+day+"&to="+day))
+	assert.Equal(t, []string{"Полить цветы", "Сдать декларацию"}, taggedTitles(t, "from="+day+"&to="+day+"&order=desc"))
+	assert.Equal(t, []string{"Сдать декларацию"}, taggedTitles(t, "from="+day+"&to="+day+"&priority=1"))
+	assert.Equal(t, []string{"Сдать декларацию"}, taggedTitles(t, "search="+url.QueryEscape("декларацию priority:1")))
+	assert.Empty(t, taggedTitles(t, "search="+url.QueryEscape("декларацию priority:2")))
+	for _, params := range []string{"priority=9", "priority=high", "priority=1&search=декларацию", "search=priority:0"} {
+		ret, err = postJSON("api/tasks?"+params, nil, http.MethodGet)
+		assert.NoError(t, err)
+		assert.NotEmpty(t, ret["error"], "Ожидается ошибка для %q", params)
+	}
+
+	// Editing without a priority keeps it; a new one replaces it.
+	ret, err = postJSON("api/task", map[string]any{"id": urgent, "date": day, "time": "2000", "title": "Сдать декларацию"}, http.MethodPut)
+	assert.NoError(t, err)
+	assert.Empty(t, ret)
+	ret, err = postJSON("api/task?id="+urgent, nil, http.MethodGet)
+	assert.NoError(t, err)
+	assert.Equal(t, "1", ret["priority"])
+	ret, err = postJSON("api/task", map[string]any{"id": urgent, "date": day, "time": "2000", "title": "Сдать декларацию",
+		"priority": "4"}, http.MethodPut)
+	assert.NoError(t, err)
+	assert.Empty(t, ret)
+	assert.Equal(t, []string{"Полить цветы", "Сдать декларацию"}, taggedTitles(t, "from="+day+"&to="+day))
+	// Priorities may be given as numbers too.
+	ret, err = postJSON("api/task", map[string]any{"id": urgent, "date": day, "time": "2000", "title": "Сдать декларацию",
+		"priority": 2}, http.MethodPut)
+	assert.NoError(t, err)
+	assert.Empty(t, ret)
+	ret, err = postJSON("api/task?id="+urgent, nil, http.MethodGet)
+	assert.NoError(t, err)
+	assert.Equal(t, "2", ret["priority"])
+	ret, err = postJSON("api/task", map[string]any{"date": day, "title": "Позвонить бухгалтеру", "priority": 1}, http.MethodPost)
+	require.NoError(t, err)
+	require.NotNil(t, ret["id"], ret)
+	call := fmt.Sprint(ret["id"])
+	defer postJSON("api/task?id="+call, nil, http.MethodDelete)
+	ret, err = postJSON("api/task?id="+call, nil, http.MethodGet)
+	assert.NoError(t, err)
+	assert.Equal(t, "1", ret["priority"])
+	ret, err = postJSON("api/task", map[string]any{"id": urgent, "date": day, "title": "Сдать декларацию",
+		"priority": "7"}, http.MethodPut)
+	assert.NoError(t, err)
+	assert.NotEmpty(t, ret["error"])
+}
@@ -0,0 +1,43 @@
+package tests
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNextDateExtended(t *testing.T) {
+	tbl := []nextDate{
+		{"20240101", "w 1 0", ""},
+		{"20240101", "w 1 53", ""},
+		{"20240101", "w 1,4 x", ""},
+		{"20240101", "w 1,4 2", "20240129"},
+		{"20240125", "w 1,4 2", "20240205"},
+		{"20240101", "w 3 3", "20240214"},
+		{"20240101", "m 2#0", ""},
+		{"20240101", "m 2#6", ""},
+		{"20240101", "m 8#1", ""},
+		{"20240101", "m 2#2", "20240213"},
+		{"20240101", "m 5#-1", "20240223"},
+		{"20240101", "m 1#1 3,6", "20240304"},
+		{"20240101", "m 15,5#-1", "20240215"},
+		{"20240101", "m 31 2", ""},
+	}
+	for _, v := range tbl {
+		urlPath := fmt.Sprintf("api/nextdate?now=20240126&date=%s&repeat=%s",
+			url.QueryEscape(v.date), url.QueryEscape(v.repeat))
+		get, err := getBody(urlPath)
+		assert.NoError(t, err)
+		next := strings.TrimSpace(string(get))
+		_, err = time.Parse("20060102", next)
+		if err != nil && len(v.want) == 0 {
+			continue
+		}
+		assert.Equal(t, v.want, next, `{%q, %q, %q}`,
+			v.date, v.repeat, v.want)
+	}
+}
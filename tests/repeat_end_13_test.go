@@ -0,0 +1,145 @@
+package tests
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRepeatEnd(t *testing.T) {
+	db := openDB(t)
+	defer db.Close()
+
+	now := time.Now()
+	for _, repeat := range []string{"d 1 count 0", "d 1 count", "d 1 until 2024", "y until 20200101", "d 1 count 2 until 20990101"} {
+		m, err := postJSON("api/task", map[string]any{
+			"date":   now.Format(`20060102`),
+			"title":  "Конечная задача",
+			"repeat": repeat,
+		}, http.MethodPost)
+		assert.NoError(t, err)
+		assert.NotEmpty(t, m["error"], "Ожидается ошибка для правила %q", repeat)
+	}
+
+	done := func(id string) {
+		ret, err := postJSON("api/task/done?id="+id, nil, http.MethodPost)
+		assert.NoError(t, err)
+		assert.Empty(t, ret)
+	}
+
+	id := addTask(t, task{
+		date:   now.Format(`20060102`),
+		title:  "Дважды",
+		repeat: "d 1 count 2",
+	})
+	done(id)
+	var stored Task
+	err := db.Get(&stored, `SELECT * FROM scheduler WHERE id=?`, id)
+	assert.NoError(t, err)
+	assert.Equal(t, now.AddDate(0, 0, 1).Format(`20060102`), stored.Date)
+	assert.Equal(t, 2, stored.Occurrence)
+	// The occurrence is written as a string and read back in either form.
+	m, err := postJSON("api/task?id="+id, nil, http.MethodGet)
+	assert.NoError(t, err)
+	assert.Equal(t, "2", m["occurrence"])
+	date := m["date"]
+	for _, occurrence := range []any{"2", 2} {
+		m, err = postJSON("api/task", map[string]any{"id": id, "date": date, "title": "Дважды",
+			"repeat": "d 1 count 2", "occurrence": occurrence}, http.MethodPut)
+		assert.NoError(t, err)
+		assert.Empty(t, m)
+	}
+	done(id)
+	notFoundTask(t, id)
+
+	// Occurrences skipped when a past date is rolled forward count too.
+	id = addTask(t, task{
+		date:   now.AddDate(0, 0, -2).Format(`20060102`),
+		title:  "Четырежды с прошлого",
+		repeat: "d 1 count 4",
+	})
+	err = db.Get(&stored, `SELECT * FROM scheduler WHERE id=?`, id)
+	assert.NoError(t, err)
+	assert.Equal(t, now.AddDate(0, 0, 1).Format(`20060102`), stored.Date)
+	assert.Equal(t, 4, stored.Occurrence)
+	done(id)
+	notFoundTask(t, id)
+	m, err = postJSON("api/task", map[string]any{
+		"date":   now.AddDate(0, 0, -2).Format(`20060102`),
+		"title":  "Трижды с прошлого",
+		"repeat": "d 1 count 3",
+	}, http.MethodPost)
+	assert.NoError(t, err)
+	assert.NotEmpty(t, m["error"])
+
+	// The agenda shows only the occurrences that are left.
+	id = addTask(t, task{
+		date:   now.Format(`20060102`),
+		title:  "Трижды в повестке",
+		repeat: "d 1 count 3",
+	})
+	assert.Equal(t, []string{now.Format(`20060102`), now.AddDate(0, 0, 1).Format(`20060102`),
+		now.AddDate(0, 0, 2).Format(`20060102`)}, agendaDates(t, id, now, now.AddDate(0, 0, 6)))
+	done(id)
+	assert.Equal(t, []string{now.AddDate(0, 0, 1).Format(`20060102`), now.AddDate(0, 0, 2).Format(`20060102`)},
+		agendaDates(t, id, now, now.AddDate(0, 0, 6)))
+	done(id)
+	assert.Equal(t, []string{now.AddDate(0, 0, 2).Format(`20060102`)}, agendaDates(t, id, now, now.AddDate(0, 0, 6)))
+	done(id)
+	notFoundTask(t, id)
+
+	id = addTask(t, task{
+		date:   now.Format(`20060102`),
+		title:  "До даты",
+		repeat: "d 3 until " + now.AddDate(0, 0, 4).Format(`20060102`),
+	})
+	done(id)
+	err = db.Get(&stored, `SELECT * FROM scheduler WHERE id=?`, id)
+	assert.NoError(t, err)
+	assert.Equal(t, now.AddDate(0, 0, 3).Format(`20060102`), stored.Date)
+	done(id)
+	notFoundTask(t, id)
+
+	id = addTask(t, task{
+		date:   now.Format(`20060102`),
+		title:  "Трижды по RRULE",
+		repeat: "RRULE:FREQ=DAILY;COUNT=3",
+	})
+	for i := 0; i < 2; i++ {
+		done(id)
+	}
+	err = db.Get(&stored, `SELECT * FROM scheduler WHERE id=?`, id)
+	assert.NoError(t, err)
+	assert.Equal(t, 3, stored.Occurrence)
+	done(id)
+	notFoundTask(t, id)
+}
+
+// agendaDates returns the dates the task id is on in the agenda from from to
+// to.
+func agendaDates(t *testing.T, id string, from, to time.Time) []string {
+	t.Helper()
+	body, err := requestJSON("api/agenda?from="+from.Format(`20060102`)+"&to="+to.Format(`20060102`), nil, http.MethodGet)
+	assert.NoError(t, err)
+	var resp struct {
+		Days []struct {
+			Date  string `json:"date"`
+			Tasks []struct {
+				ID string `json:"id"`
+			} `json:"tasks"`
+		} `json:"days"`
+	}
+	assert.NoError(t, json.Unmarshal(body, &resp))
+	var dates []string
+	for _, day := range resp.Days {
+		for _, task := range day.Tasks {
+			if task.ID == id {
+				dates = append(dates, day.Date)
+			}
+		}
+	}
+	return dates
+}
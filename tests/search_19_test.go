@@ -0,0 +1,59 @@
+package tests
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func searchTasks(t *testing.T, search string) []map[string]string {
+	body, err := requestJSON("api/tasks?search="+url.QueryEscape(search), nil, http.MethodGet)
+	assert.NoError(t, err)
+
+	var m map[string][]map[string]string
+	err = json.Unmarshal(body, &m)
+	assert.NoError(t, err)
+	return m["tasks"]
+}
+
+func titles(list []map[string]string) []string {
+	result := make([]string, len(list))
+	for i, task := range list {
+		result[i] = task["title"]
+	}
+	return result
+}
+
+func TestSearch(t *testing.T) {
+	ids := []string{
+		addTask(t, task{date: "21000101", title: "Купить молоко зюзябре"}),
+		addTask(t, task{date: "21000101", title: "Позвонить маме", comment: "<b>зюзябре</b> и молоко"}),
+		addTask(t, task{date: "21000101", title: "Покормить зюзябру"}),
+		addTask(t, task{date: "21000101", title: "Send zyuzyabr reports"}),
+	}
+	defer func() {
+		for _, id := range ids {
+			_, err := postJSON("api/task?id="+id, nil, http.MethodDelete)
+			assert.NoError(t, err)
+		}
+	}()
+
+	assert.Len(t, searchTasks(t, "зюзябр*"), 3)
+	assert.Equal(t, []string{"Купить молоко зюзябре"}, titles(searchTasks(t, `"молоко зюзябре"`)))
+	assert.ElementsMatch(t, []string{"Купить молоко зюзябре", "Позвонить маме"}, titles(searchTasks(t, "зюзябре молоко")))
+	assert.Empty(t, searchTasks(t, "зюзябре кефир"))
+	assert.NotNil(t, searchTasks(t, `"" *`))
+	// English words are found in other forms.
+	assert.Equal(t, []string{"Send zyuzyabr reports"}, titles(searchTasks(t, "zyuzyabr report")))
+
+	// Ranking and snippets come with the full-text index only.
+	found := searchTasks(t, "зюзябре")
+	if assert.Len(t, found, 2) && found[0]["snippet"] != "" {
+		assert.Equal(t, []string{"Купить молоко зюзябре", "Позвонить маме"}, titles(found))
+		assert.Contains(t, found[0]["snippet"], "<mark>зюзябре</mark>")
+		assert.Contains(t, found[1]["snippet"], "&lt;b&gt;<mark>зюзябре</mark>&lt;/b&gt;")
+	}
+}
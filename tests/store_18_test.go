@@ -0,0 +1,689 @@
+package tests
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"main/core/database/memory"
+	"main/core/database/postgres"
+	"main/core/database/sqlite"
+	"main/core/database/store"
+	"main/internal/models/attachments"
+	"main/internal/models/checklists"
+	"main/internal/models/holidays"
+	"main/internal/models/lists"
+	"main/internal/models/tags"
+	"main/internal/models/tasks"
+	"main/internal/models/users"
+)
+
+// storeTests are run against every backend, each with a fresh store.
+var storeTests = []struct {
+	name string
+	test func(*testing.T, store.Store)
+}{
+	{"tasks", testStoreTasks},
+	{"pagination", testStorePagination},
+	{"search", testStoreSearch},
+	{"trash", testStoreTrash},
+	{"holidays", testStoreHolidays},
+	{"lists", testStoreLists},
+	{"tags", testStoreTags},
+	{"priorities", testStorePriorities},
+	{"checklists", testStoreChecklists},
+	{"users", testStoreUsers},
+	{"dependencies", testStoreDependencies},
+	{"attachments", testStoreAttachments},
+}
+
+func TestStores(t *testing.T) {
+	// TODO_TEST_POSTGRES_DSN is a postgres:// URL of a database the test
+	// may create scratch schemas in.
+	dsn := os.Getenv("TODO_TEST_POSTGRES_DSN")
+	var noPostgres string
+	if dsn == "" {
+		noPostgres = "TODO_TEST_POSTGRES_DSN is not set"
+	}
+	backends := []struct {
+		name string
+		// skip is why the backend cannot be tested, if it cannot.
+		skip string
+		open func(*testing.T) store.Store
+	}{
+		{name: "memory", open: func(t *testing.T) store.Store {
+			return memory.New()
+		}},
+		{name: "sqlite", open: func(t *testing.T) store.Store {
+			s, err := sqlite.New(filepath.Join(t.TempDir(), "store.db"))
+			require.NoError(t, err)
+			return s
+		}},
+		{name: "postgres", skip: noPostgres, open: func(t *testing.T) store.Store {
+			return openPostgresStore(t, dsn)
+		}},
+	}
+	for _, backend := range backends {
+		t.Run(backend.name, func(t *testing.T) {
+			if backend.skip != "" {
+				t.Skip(backend.skip)
+			}
+			for _, v := range storeTests {
+				t.Run(v.name, func(t *testing.T) {
+					s := backend.open(t)
+					defer s.Close()
+					v.test(t, s)
+				})
+			}
+		})
+	}
+}
+
+// openPostgresStore opens a store in a scratch schema of the database dsn
+// that is dropped when the test ends.
+func openPostgresStore(t *testing.T, dsn string) store.Store {
+	s, err := postgres.New(postgresSchema(t, dsn))
+	require.NoError(t, err)
+	return s
+}
+
+// postgresSchema creates a scratch schema in the database dsn that is dropped
+// when the test ends, and returns a dsn with the schema on its search path.
+func postgresSchema(t *testing.T, dsn string) string {
+	schema := fmt.Sprintf("todo_test_%d", time.Now().UnixNano())
+	db, err := postgres.Open(dsn)
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+	_, err = db.Exec("CREATE SCHEMA " + schema)
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Exec("DROP SCHEMA " + schema + " CASCADE") })
+
+	u, err := url.Parse(dsn)
+	require.NoError(t, err)
+	query := u.Query()
+	query.Set("search_path", schema)
+	u.RawQuery = query.Encode()
+	return u.String()
+}
+
+// addStoreTasks adds a weekly task and a one-off task on 21000101 and a
+// one-off task on 21000105, and returns their ids.
+func addStoreTasks(t *testing.T, s store.Store) (weeklyID, onceID, laterID string) {
+	t.Helper()
+	weekly, err := s.AddTaskDB(tasks.Task{Date: "21000101", Title: "Планёрка", Repeat: "d 7", Time: "0930"})
+	require.NoError(t, err)
+	once, err := s.AddTaskDB(tasks.Task{Date: "21000101", Title: "Отчёт", Comment: "квартальный"})
+	require.NoError(t, err)
+	later, err := s.AddTaskDB(tasks.Task{Date: "21000105", Title: "Звонок"})
+	require.NoError(t, err)
+	return fmt.Sprint(weekly), fmt.Sprint(once), fmt.Sprint(later)
+}
+
+func testStoreTasks(t *testing.T, s store.Store) {
+	weeklyID, onceID, _ := addStoreTasks(t, s)
+
+	task, err := s.FindTask(weeklyID)
+	require.NoError(t, err)
+	assert.Equal(t, "Планёрка", task.Title)
+	assert.Equal(t, 1, task.Occurrence)
+	_, err = s.FindTask("999999")
+	assert.ErrorIs(t, err, store.ErrNoSuchTask)
+
+	list, err := s.TasksByDate("21000101")
+	require.NoError(t, err)
+	assert.Equal(t, []string{onceID, weeklyID}, ids(list))
+
+	list, err = s.TasksUntil("21000104")
+	require.NoError(t, err)
+	assert.Len(t, list, 2)
+
+	require.NoError(t, s.DoneTask(weeklyID, "провели"))
+	task, err = s.FindTask(weeklyID)
+	require.NoError(t, err)
+	assert.Equal(t, "21000108", task.Date)
+	assert.Equal(t, 2, task.Occurrence)
+
+	task.Repeat = "d 14"
+	require.NoError(t, s.UpdateTask(*task))
+	task, err = s.FindTask(weeklyID)
+	require.NoError(t, err)
+	assert.Equal(t, 1, task.Occurrence)
+
+	require.NoError(t, s.DoneTask(onceID, ""))
+	_, err = s.FindTask(onceID)
+	assert.ErrorIs(t, err, store.ErrNoSuchTask)
+
+	history, err := s.TaskHistory(weeklyID)
+	require.NoError(t, err)
+	require.Len(t, history, 1)
+	assert.Equal(t, "21000101", history[0].Date)
+	assert.Equal(t, "провели", history[0].Note)
+	done, err := s.Completions(time.Now().Add(-time.Hour), time.Now().Add(time.Hour))
+	require.NoError(t, err)
+	assert.Len(t, done, 2)
+}
+
+func testStorePagination(t *testing.T, s store.Store) {
+	weeklyID, onceID, laterID := addStoreTasks(t, s)
+
+	page, err := s.Tasks(store.TaskQuery{})
+	require.NoError(t, err)
+	assert.Equal(t, []string{onceID, weeklyID, laterID}, ids(page.Tasks))
+	assert.Equal(t, 3, page.Total)
+	assert.Nil(t, page.Next)
+
+	oneOff := false
+	for _, c := range []struct {
+		query store.TaskQuery
+		ids   []string
+	}{
+		{store.TaskQuery{Desc: true}, []string{laterID, weeklyID, onceID}},
+		{store.TaskQuery{Sort: store.SortTitle}, []string{laterID, onceID, weeklyID}},
+		{store.TaskQuery{Sort: store.SortID, Desc: true}, []string{laterID, onceID, weeklyID}},
+		{store.TaskQuery{From: "21000102"}, []string{laterID}},
+		{store.TaskQuery{To: "21000101", Recurring: &oneOff}, []string{onceID}},
+		{store.TaskQuery{Page: store.Page{Limit: 2, Offset: 1}}, []string{weeklyID, laterID}},
+	} {
+		page, err = s.Tasks(c.query)
+		require.NoError(t, err)
+		assert.Equal(t, c.ids, ids(page.Tasks), "%+v", c.query)
+	}
+	_, err = s.Tasks(store.TaskQuery{Sort: "repeat"})
+	assert.Error(t, err)
+
+	// Walking a list with cursors gives the same tasks as a single page.
+	for _, query := range []store.TaskQuery{{}, {Desc: true}, {Sort: store.SortTitle}, {Sort: store.SortID, Desc: true}} {
+		page, err = s.Tasks(query)
+		require.NoError(t, err)
+		all := ids(page.Tasks)
+
+		var walked []string
+		query.Limit = 1
+		for {
+			page, err = s.Tasks(query)
+			require.NoError(t, err)
+			assert.Equal(t, 3, page.Total)
+			walked = append(walked, ids(page.Tasks)...)
+			if page.Next == nil || len(walked) > len(all) {
+				break
+			}
+			query.After, err = store.ParseCursor(page.Next.String())
+			require.NoError(t, err)
+		}
+		assert.Equal(t, all, walked, "%+v", query)
+	}
+	page, err = s.Tasks(store.TaskQuery{Page: store.Page{Limit: 1}})
+	require.NoError(t, err)
+	require.NotNil(t, page.Next)
+	_, err = s.Tasks(store.TaskQuery{Sort: store.SortTitle, Page: store.Page{After: page.Next}})
+	assert.ErrorIs(t, err, store.ErrInvalidCursor)
+	_, err = store.ParseCursor("bm90IGEgY3Vyc29y")
+	assert.ErrorIs(t, err, store.ErrInvalidCursor)
+}
+
+func testStoreSearch(t *testing.T, s store.Store) {
+	weeklyID, onceID, laterID := addStoreTasks(t, s)
+
+	search := func(query string) []string {
+		filter, err := store.ParseFilter(query)
+		require.NoError(t, err)
+		page, err := s.SearchTasks(filter, store.Page{})
+		require.NoError(t, err)
+		return ids(page.Tasks)
+	}
+	assert.Equal(t, []string{onceID}, search("квартальный"))
+	assert.Equal(t, []string{weeklyID}, search("-квартальный before:21000102"))
+	assert.Equal(t, []string{onceID, weeklyID}, search("after:21000101 before:21000105"))
+	assert.Equal(t, []string{laterID}, search("after:21000102"))
+	assert.Equal(t, []string{weeklyID}, search("repeat:yes"))
+	assert.Equal(t, []string{onceID}, search("repeat:no before:02.01.2100"))
+}
+
+func testStoreTrash(t *testing.T, s store.Store) {
+	_, onceID, laterID := addStoreTasks(t, s)
+
+	require.NoError(t, s.DoneTask(onceID, ""))
+	require.NoError(t, s.DeleteTask(laterID))
+	assert.ErrorIs(t, s.DeleteTask(laterID), store.ErrNoSuchTask)
+	trash, err := s.Trash()
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{onceID, laterID}, ids(trash))
+
+	require.NoError(t, s.RestoreTask(laterID))
+	assert.ErrorIs(t, s.RestoreTask(laterID), store.ErrNoSuchTask)
+	require.NoError(t, s.PurgeTask(onceID))
+	assert.ErrorIs(t, s.PurgeTask(onceID), store.ErrNoSuchTask)
+	require.NoError(t, s.DeleteTask(laterID))
+	n, err := s.PurgeTrash(time.Now().Add(-time.Hour))
+	require.NoError(t, err)
+	assert.Zero(t, n)
+	n, err = s.PurgeTrash(time.Time{})
+	require.NoError(t, err)
+	assert.EqualValues(t, 1, n)
+}
+
+func testStoreHolidays(t *testing.T, s store.Store) {
+	require.NoError(t, s.AddHoliday(holidays.Holiday{Date: "21000301", Name: "Праздник"}))
+	days, err := s.Holidays()
+	require.NoError(t, err)
+	assert.Equal(t, []holidays.Holiday{{Date: "21000301", Name: "Праздник"}}, days)
+	shifted, err := s.AddTaskDB(tasks.Task{Date: "21000301", Title: "Сдать отчёт", Shift: "next"})
+	require.NoError(t, err)
+	task, err := s.FindTask(fmt.Sprint(shifted))
+	require.NoError(t, err)
+	assert.Equal(t, "21000302", task.Date)
+	assert.Equal(t, "21000301", task.Anchor)
+	require.NoError(t, s.DeleteHoliday("21000301"))
+	assert.ErrorIs(t, s.DeleteHoliday("21000301"), store.ErrNoSuchHoliday)
+}
+
+func testStoreLists(t *testing.T, s store.Store) {
+	weeklyID, _, _ := addStoreTasks(t, s)
+
+	work, err := s.AddList(lists.List{Name: "Работа"})
+	require.NoError(t, err)
+	_, err = s.AddList(lists.List{Name: "Работа"})
+	assert.ErrorIs(t, err, store.ErrListExists)
+	home, err := s.AddList(lists.List{Name: "Дом"})
+	require.NoError(t, err)
+	_, err = s.AddTaskDB(tasks.Task{Date: "21000101", Title: "Чужой список", ListID: home + work})
+	assert.ErrorIs(t, err, store.ErrNoSuchList)
+	listed, err := s.AddTaskDB(tasks.Task{Date: "21000101", Title: "Отчёт по работе", ListID: work})
+	require.NoError(t, err)
+	require.NoError(t, s.MoveTask(weeklyID, work))
+	assert.ErrorIs(t, s.MoveTask(weeklyID, home+work), store.ErrNoSuchList)
+	page, err := s.Tasks(store.TaskQuery{List: work})
+	require.NoError(t, err)
+	assert.Equal(t, []string{fmt.Sprint(listed), weeklyID}, ids(page.Tasks))
+	named, err := s.Lists()
+	require.NoError(t, err)
+	assert.Equal(t, []lists.List{{ID: fmt.Sprint(home), Name: "Дом"}, {ID: fmt.Sprint(work), Name: "Работа", Tasks: 2}}, named)
+	require.NoError(t, s.UpdateList(lists.List{ID: fmt.Sprint(home), Name: "Дача"}))
+	assert.ErrorIs(t, s.UpdateList(lists.List{ID: fmt.Sprint(home), Name: "Работа"}), store.ErrListExists)
+	require.NoError(t, s.MoveTask(weeklyID, 0))
+	require.NoError(t, s.DeleteList(fmt.Sprint(work)))
+	assert.ErrorIs(t, s.DeleteList(fmt.Sprint(work)), store.ErrNoSuchList)
+	task, err := s.FindTask(fmt.Sprint(listed))
+	require.NoError(t, err)
+	assert.Zero(t, task.ListID)
+}
+
+func testStoreTags(t *testing.T, s store.Store) {
+	// Tags are created on first use, and a task's tags are kept when it is
+	// updated without them.
+	tagged, err := s.AddTaskDB(tasks.Task{Date: "21000101", Title: "Квартальный отчёт", Tags: []string{"отчёт", "работа"}})
+	require.NoError(t, err)
+	plain, err := s.AddTaskDB(tasks.Task{Date: "21000102", Title: "Отчёт по дому", Tags: []string{"дом"}})
+	require.NoError(t, err)
+	task, err := s.FindTask(fmt.Sprint(tagged))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"отчёт", "работа"}, task.Tags)
+	task.Title = "Годовой отчёт"
+	task.Tags = nil
+	require.NoError(t, s.UpdateTask(*task))
+	task, err = s.FindTask(fmt.Sprint(tagged))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"отчёт", "работа"}, task.Tags)
+	page, err := s.Tasks(store.TaskQuery{Tag: "работа"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{fmt.Sprint(tagged)}, ids(page.Tasks))
+	filter, err := store.ParseFilter("дому tag:дом")
+	require.NoError(t, err)
+	found, err := s.SearchTasks(filter, store.Page{})
+	require.NoError(t, err)
+	assert.Equal(t, []string{fmt.Sprint(plain)}, ids(found.Tasks))
+	marks, err := s.Tags()
+	require.NoError(t, err)
+	require.Len(t, marks, 3)
+	assert.Equal(t, []string{"дом", "отчёт", "работа"}, []string{marks[0].Name, marks[1].Name, marks[2].Name})
+	assert.Equal(t, 1, marks[2].Tasks)
+	_, err = s.AddTag(tags.Tag{Name: "дом"})
+	assert.ErrorIs(t, err, store.ErrTagExists)
+	assert.ErrorIs(t, s.UpdateTag(tags.Tag{ID: marks[0].ID, Name: "работа"}), store.ErrTagExists)
+	require.NoError(t, s.UpdateTag(tags.Tag{ID: marks[0].ID, Name: "дача"}))
+	task, err = s.FindTask(fmt.Sprint(plain))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"дача"}, task.Tags)
+	require.NoError(t, s.MergeTags(marks[1].ID, marks[2].ID))
+	assert.ErrorIs(t, s.MergeTags(marks[1].ID, marks[2].ID), store.ErrNoSuchTag)
+	task, err = s.FindTask(fmt.Sprint(tagged))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"работа"}, task.Tags)
+	require.NoError(t, s.DeleteTag(marks[0].ID))
+	assert.ErrorIs(t, s.DeleteTag(marks[0].ID), store.ErrNoSuchTag)
+	task, err = s.FindTask(fmt.Sprint(plain))
+	require.NoError(t, err)
+	assert.Empty(t, task.Tags)
+	task.Tags = []string{}
+	require.NoError(t, s.UpdateTask(*task))
+	marks, err = s.Tags()
+	require.NoError(t, err)
+	assert.Equal(t, []tags.Tag{{ID: marks[0].ID, Name: "работа", Tasks: 1}}, marks)
+}
+
+func testStorePriorities(t *testing.T, s store.Store) {
+	// Tasks of a day are ordered by priority before time, and a priority is
+	// kept when a task is updated without one.
+	routine, err := s.AddTaskDB(tasks.Task{Date: "21000201", Time: "0900", Title: "Разобрать почту"})
+	require.NoError(t, err)
+	urgent, err := s.AddTaskDB(tasks.Task{Date: "21000201", Time: "1800", Title: "Срочный отчёт", Priority: 1})
+	require.NoError(t, err)
+	task, err := s.FindTask(fmt.Sprint(routine))
+	require.NoError(t, err)
+	assert.Equal(t, tasks.PriorityLowest, task.Priority)
+	page, err := s.Tasks(store.TaskQuery{From: "21000201", To: "21000201"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{fmt.Sprint(urgent), fmt.Sprint(routine)}, ids(page.Tasks))
+	first, err := s.Tasks(store.TaskQuery{From: "21000201", To: "21000201", Page: store.Page{Limit: 1}})
+	require.NoError(t, err)
+	require.NotNil(t, first.Next)
+	page, err = s.Tasks(store.TaskQuery{From: "21000201", To: "21000201", Page: store.Page{Limit: 1, After: first.Next}})
+	require.NoError(t, err)
+	assert.Equal(t, []string{fmt.Sprint(routine)}, ids(page.Tasks))
+	day, err := s.TasksByDate("21000201")
+	require.NoError(t, err)
+	assert.Equal(t, []string{fmt.Sprint(urgent), fmt.Sprint(routine)}, ids(day))
+	task, err = s.FindTask(fmt.Sprint(urgent))
+	require.NoError(t, err)
+	task.Priority = 0
+	task.Title = "Срочный квартальный отчёт"
+	require.NoError(t, s.UpdateTask(*task))
+	page, err = s.Tasks(store.TaskQuery{Priority: 1})
+	require.NoError(t, err)
+	assert.Equal(t, []string{fmt.Sprint(urgent)}, ids(page.Tasks))
+	filter, err := store.ParseFilter("priority:4 почту")
+	require.NoError(t, err)
+	found, err := s.SearchTasks(filter, store.Page{})
+	require.NoError(t, err)
+	assert.Equal(t, []string{fmt.Sprint(routine)}, ids(found.Tasks))
+}
+
+func testStoreChecklists(t *testing.T, s store.Store) {
+	// Checklist items keep their order, and rolling a repeating task forward
+	// unchecks them.
+	daily, err := s.AddTaskDB(tasks.Task{Date: "21000401", Title: "Зарядка", Repeat: "d 1"})
+	require.NoError(t, err)
+	dailyID := fmt.Sprint(daily)
+	var steps []string
+	for _, text := range []string{"Разминка", "Приседания", "Растяжка"} {
+		item, err := s.AddItem(dailyID, text)
+		require.NoError(t, err)
+		steps = append(steps, fmt.Sprint(item))
+	}
+	_, err = s.AddItem(fmt.Sprint(daily+1000), "Лишний")
+	assert.ErrorIs(t, err, store.ErrNoSuchTask)
+	require.NoError(t, s.ReorderChecklist(dailyID, []string{steps[2], steps[0], steps[1]}))
+	assert.ErrorIs(t, s.ReorderChecklist(dailyID, []string{steps[0], steps[1]}), store.ErrInvalidOrder)
+	assert.ErrorIs(t, s.ReorderChecklist(dailyID, []string{steps[0], steps[0], steps[1]}), store.ErrInvalidOrder)
+	checked, err := s.ToggleItem(steps[0])
+	require.NoError(t, err)
+	assert.True(t, checked)
+	_, err = s.ToggleItem(steps[2])
+	require.NoError(t, err)
+	items, err := s.Checklist(dailyID)
+	require.NoError(t, err)
+	assert.Equal(t, []checklists.Item{
+		{ID: steps[2], TaskID: daily, Position: 1, Text: "Растяжка", Done: true},
+		{ID: steps[0], TaskID: daily, Position: 2, Text: "Разминка", Done: true},
+		{ID: steps[1], TaskID: daily, Position: 3, Text: "Приседания"},
+	}, items)
+	require.NoError(t, s.DoneTask(dailyID, ""))
+	items, err = s.Checklist(dailyID)
+	require.NoError(t, err)
+	require.Len(t, items, 3)
+	for _, item := range items {
+		assert.False(t, item.Done, item.Text)
+	}
+	require.NoError(t, s.DeleteItem(steps[1]))
+	assert.ErrorIs(t, s.DeleteItem(steps[1]), store.ErrNoSuchItem)
+	_, err = s.ToggleItem(steps[1])
+	assert.ErrorIs(t, err, store.ErrNoSuchItem)
+	last, err := s.AddItem(dailyID, "Душ")
+	require.NoError(t, err)
+	items, err = s.Checklist(dailyID)
+	require.NoError(t, err)
+	assert.Equal(t, []string{steps[2], steps[0], fmt.Sprint(last)}, itemIDs(items))
+	// Items of a task in the trash are out of reach until it is restored.
+	require.NoError(t, s.DeleteTask(dailyID))
+	_, err = s.Checklist(dailyID)
+	assert.ErrorIs(t, err, store.ErrNoSuchTask)
+	_, err = s.ToggleItem(steps[0])
+	assert.ErrorIs(t, err, store.ErrNoSuchItem)
+	require.NoError(t, s.RestoreTask(dailyID))
+	items, err = s.Checklist(dailyID)
+	require.NoError(t, err)
+	assert.Len(t, items, 3)
+}
+
+func testStoreUsers(t *testing.T, s store.Store) {
+	weekly, err := s.AddTaskDB(tasks.Task{Date: "21000101", Title: "Планёрка", Repeat: "d 7", Tags: []string{"работа"}})
+	require.NoError(t, err)
+	weeklyID := fmt.Sprint(weekly)
+	require.NoError(t, s.DoneTask(weeklyID, ""))
+	home, err := s.AddList(lists.List{Name: "Дом"})
+	require.NoError(t, err)
+	daily, err := s.AddTaskDB(tasks.Task{Date: "21000401", Title: "Зарядка", Repeat: "d 1"})
+	require.NoError(t, err)
+	dailyID := fmt.Sprint(daily)
+	step, err := s.AddItem(dailyID, "Разминка")
+	require.NoError(t, err)
+	stepID := fmt.Sprint(step)
+
+	alice, err := s.AddUser(users.User{Login: "alice", PasswordHash: "hash"})
+	require.NoError(t, err)
+	_, err = s.AddUser(users.User{Login: "alice", PasswordHash: "other"})
+	assert.ErrorIs(t, err, store.ErrUserExists)
+	user, err := s.FindUserByLogin("alice")
+	require.NoError(t, err)
+	assert.Equal(t, fmt.Sprint(alice), user.ID)
+	assert.Equal(t, "hash", user.PasswordHash)
+	_, err = s.FindUser(alice + 1)
+	assert.ErrorIs(t, err, store.ErrNoSuchUser)
+
+	// A user sees neither the owner's tasks nor their history, and the owner
+	// does not see the user's.
+	mine := s.ForUser(alice)
+	_, err = mine.FindTask(weeklyID)
+	assert.ErrorIs(t, err, store.ErrNoSuchTask)
+	assert.ErrorIs(t, mine.DoneTask(weeklyID, ""), store.ErrNoSuchTask)
+	assert.ErrorIs(t, mine.DeleteTask(weeklyID), store.ErrNoSuchTask)
+	assert.ErrorIs(t, mine.DeleteList(fmt.Sprint(home)), store.ErrNoSuchList)
+	named, err := mine.Lists()
+	require.NoError(t, err)
+	assert.Empty(t, named)
+	_, err = mine.Checklist(dailyID)
+	assert.ErrorIs(t, err, store.ErrNoSuchTask)
+	_, err = mine.AddItem(dailyID, "Чужой пункт")
+	assert.ErrorIs(t, err, store.ErrNoSuchTask)
+	_, err = mine.ToggleItem(stepID)
+	assert.ErrorIs(t, err, store.ErrNoSuchItem)
+	assert.ErrorIs(t, mine.DeleteItem(stepID), store.ErrNoSuchItem)
+	marks, err := mine.Tags()
+	require.NoError(t, err)
+	assert.Empty(t, marks)
+	history, err := mine.TaskHistory(weeklyID)
+	require.NoError(t, err)
+	assert.Empty(t, history)
+	own, err := mine.AddTaskDB(tasks.Task{Date: "21000101", Title: "Планёрка"})
+	require.NoError(t, err)
+	page, err := mine.Tasks(store.TaskQuery{})
+	require.NoError(t, err)
+	assert.Equal(t, []string{fmt.Sprint(own)}, ids(page.Tasks))
+	filter, err := store.ParseFilter("Планёрка")
+	require.NoError(t, err)
+	found, err := mine.SearchTasks(filter, store.Page{})
+	require.NoError(t, err)
+	assert.Equal(t, []string{fmt.Sprint(own)}, ids(found.Tasks))
+	_, err = s.FindTask(fmt.Sprint(own))
+	assert.ErrorIs(t, err, store.ErrNoSuchTask)
+	require.NoError(t, mine.DoneTask(fmt.Sprint(own), ""))
+	done, err := s.Completions(time.Time{}, time.Time{})
+	require.NoError(t, err)
+	assert.Len(t, done, 1)
+	done, err = mine.Completions(time.Time{}, time.Time{})
+	require.NoError(t, err)
+	assert.Len(t, done, 1)
+	n, err := s.PurgeTrash(time.Time{})
+	require.NoError(t, err)
+	assert.Zero(t, n)
+	trash, err := mine.Trash()
+	require.NoError(t, err)
+	assert.Equal(t, []string{fmt.Sprint(own)}, ids(trash))
+
+	accounts, err := s.Users()
+	require.NoError(t, err)
+	assert.Len(t, accounts, 1)
+	require.NoError(t, s.DeleteUser(alice))
+	assert.ErrorIs(t, s.DeleteUser(alice), store.ErrNoSuchUser)
+	trash, err = mine.Trash()
+	require.NoError(t, err)
+	assert.Empty(t, trash)
+	done, err = mine.Completions(time.Time{}, time.Time{})
+	require.NoError(t, err)
+	assert.Empty(t, done)
+}
+
+func testStoreDependencies(t *testing.T, s store.Store) {
+	alice, err := s.AddUser(users.User{Login: "alice", PasswordHash: "hash"})
+	require.NoError(t, err)
+
+	// A task is blocked until its one-off blockers are done and its
+	// repeating ones move past its date.
+	buy, err := s.AddTaskDB(tasks.Task{Date: "21000501", Title: "Купить краску"})
+	require.NoError(t, err)
+	paint, err := s.AddTaskDB(tasks.Task{Date: "21000502", Title: "Покрасить забор"})
+	require.NoError(t, err)
+	bills, err := s.AddTaskDB(tasks.Task{Date: "21000501", Title: "Оплатить счета", Repeat: "d 7"})
+	require.NoError(t, err)
+	buyID, paintID, billsID := fmt.Sprint(buy), fmt.Sprint(paint), fmt.Sprint(bills)
+	require.NoError(t, s.AddDependency(paintID, buyID))
+	require.NoError(t, s.AddDependency(paintID, buyID))
+	assert.ErrorIs(t, s.AddDependency(paintID, paintID), store.ErrDependencyCycle)
+	assert.ErrorIs(t, s.AddDependency(buyID, paintID), store.ErrDependencyCycle)
+	require.NoError(t, s.AddDependency(buyID, billsID))
+	assert.ErrorIs(t, s.AddDependency(billsID, paintID), store.ErrDependencyCycle)
+	assert.ErrorIs(t, s.AddDependency(paintID, fmt.Sprint(bills+1000)), store.ErrNoSuchTask)
+	assert.ErrorIs(t, s.ForUser(alice).AddDependency(paintID, buyID), store.ErrNoSuchTask)
+	blockers, err := s.Blockers(paintID)
+	require.NoError(t, err)
+	assert.Equal(t, []string{buyID}, ids(blockers))
+	assert.True(t, blockers[0].Blocked)
+	page, err := s.Tasks(store.TaskQuery{From: "21000501", To: "21000502"})
+	require.NoError(t, err)
+	require.Equal(t, []string{buyID, billsID, paintID}, ids(page.Tasks))
+	assert.Equal(t, []bool{true, false, true}, []bool{page.Tasks[0].Blocked, page.Tasks[1].Blocked, page.Tasks[2].Blocked})
+	assert.ErrorIs(t, s.DoneTask(paintID, ""), store.ErrTaskBlocked)
+	assert.ErrorIs(t, s.DoneTask(buyID, ""), store.ErrTaskBlocked)
+
+	require.NoError(t, s.DoneTask(billsID, ""))
+	task, err := s.FindTask(buyID)
+	require.NoError(t, err)
+	assert.False(t, task.Blocked)
+	require.NoError(t, s.AddDependency(paintID, billsID))
+	require.NoError(t, s.DeleteTask(buyID))
+	task, err = s.FindTask(paintID)
+	require.NoError(t, err)
+	assert.False(t, task.Blocked)
+	task, err = s.FindTask(billsID)
+	require.NoError(t, err)
+	task.Date = "21000502"
+	require.NoError(t, s.UpdateTask(*task))
+	task, err = s.FindTask(paintID)
+	require.NoError(t, err)
+	assert.True(t, task.Blocked)
+	require.NoError(t, s.DeleteDependency(paintID, billsID))
+	assert.ErrorIs(t, s.DeleteDependency(paintID, billsID), store.ErrNoSuchDependency)
+	require.NoError(t, s.DoneTask(paintID, ""))
+	require.NoError(t, s.RestoreTask(paintID))
+	require.NoError(t, s.AddDependency(paintID, billsID))
+	require.NoError(t, s.DeleteTask(billsID))
+	require.NoError(t, s.PurgeTask(billsID))
+	task, err = s.FindTask(paintID)
+	require.NoError(t, err)
+	assert.False(t, task.Blocked)
+	blockers, err = s.Blockers(paintID)
+	require.NoError(t, err)
+	assert.Empty(t, blockers)
+}
+
+func testStoreAttachments(t *testing.T, s store.Store) {
+	alice, err := s.AddUser(users.User{Login: "alice", PasswordHash: "hash"})
+	require.NoError(t, err)
+	paint, err := s.AddTaskDB(tasks.Task{Date: "21000502", Title: "Покрасить забор"})
+	require.NoError(t, err)
+	paintID := fmt.Sprint(paint)
+
+	// Attachments of a task in the trash are out of reach, and are handed
+	// back for their files to be removed once it is purged.
+	plan, err := s.AddAttachment(attachments.Attachment{TaskID: paint, Name: "план.pdf", Size: 3, ContentType: "application/pdf", File: "plan"})
+	require.NoError(t, err)
+	photo, err := s.AddAttachment(attachments.Attachment{TaskID: paint, Name: "забор.jpg", Size: 5, ContentType: "image/jpeg", File: "photo"})
+	require.NoError(t, err)
+	planID, photoID := fmt.Sprint(plan), fmt.Sprint(photo)
+	_, err = s.AddAttachment(attachments.Attachment{TaskID: paint + 1000, Name: "счёт.pdf", File: "bill"})
+	assert.ErrorIs(t, err, store.ErrNoSuchTask)
+	_, err = s.ForUser(alice).AddAttachment(attachments.Attachment{TaskID: paint, Name: "чужой.pdf", File: "other"})
+	assert.ErrorIs(t, err, store.ErrNoSuchTask)
+	attached, err := s.Attachments(paintID)
+	require.NoError(t, err)
+	require.Equal(t, []string{planID, photoID}, attachmentIDs(attached))
+	assert.Equal(t, "план.pdf", attached[0].Name)
+	assert.EqualValues(t, 3, attached[0].Size)
+	assert.Equal(t, "application/pdf", attached[0].ContentType)
+	assert.Equal(t, "plan", attached[0].File)
+	assert.NotEmpty(t, attached[0].CreatedAt)
+	attachment, err := s.FindAttachment(photoID)
+	require.NoError(t, err)
+	assert.Equal(t, "photo", attachment.File)
+	_, err = s.ForUser(alice).FindAttachment(photoID)
+	assert.ErrorIs(t, err, store.ErrNoSuchAttachment)
+	assert.ErrorIs(t, s.ForUser(alice).DeleteAttachment(photoID), store.ErrNoSuchAttachment)
+	require.NoError(t, s.DeleteAttachment(photoID))
+	assert.ErrorIs(t, s.DeleteAttachment(photoID), store.ErrNoSuchAttachment)
+
+	require.NoError(t, s.DeleteTask(paintID))
+	_, err = s.Attachments(paintID)
+	assert.ErrorIs(t, err, store.ErrNoSuchTask)
+	_, err = s.FindAttachment(planID)
+	assert.ErrorIs(t, err, store.ErrNoSuchAttachment)
+	purged, err := s.PurgeAttachments()
+	require.NoError(t, err)
+	assert.Empty(t, purged)
+	require.NoError(t, s.PurgeTask(paintID))
+	purged, err = s.PurgeAttachments()
+	require.NoError(t, err)
+	assert.Equal(t, []string{planID}, attachmentIDs(purged))
+	assert.Equal(t, "plan", purged[0].File)
+	purged, err = s.PurgeAttachments()
+	require.NoError(t, err)
+	assert.Empty(t, purged)
+}
+func ids(list []tasks.Task) []string {
+	result := make([]string, len(list))
+	for i, task := range list {
+		result[i] = task.ID
+	}
+	return result
+}
+
+func itemIDs(list []checklists.Item) []string {
+	result := make([]string, len(list))
+	for i, item := range list {
+		result[i] = item.ID
+	}
+	return result
+}
+
+func attachmentIDs(list []attachments.Attachment) []string {
+	result := make([]string, len(list))
+	for i, attachment := range list {
+		result[i] = attachment.ID
+	}
+	return result
+}
@@ -0,0 +1,111 @@
+package tests
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// findTag returns the tag with the given name from /api/tags.
+func findTag(t *testing.T, name string) map[string]any {
+	t.Helper()
+	ret, err := postJSON("api/tags", nil, http.MethodGet)
+	require.NoError(t, err)
+	list, _ := ret["tags"].([]any)
+	for _, item := range list {
+		if found := item.(map[string]any); found["name"] == name {
+			return found
+		}
+	}
+	return nil
+}
+
+func taggedTitles(t *testing.T, params string) []string {
+	t.Helper()
+	var result []string
+	ret, err := postJSON("api/tasks?"+params, nil, http.MethodGet)
+	require.NoError(t, err)
+	require.Empty(t, ret["error"])
+	items, _ := ret["tasks"].([]any)
+	for _, item := range items {
+		result = append(result, item.(map[string]any)["title"].(string))
+	}
+	return result
+}
+
+func TestTags(t *testing.T) {
+	suffix := fmt.Sprint(time.Now().UnixNano())
+	work, home := "работа"+suffix, "дом"+suffix
+
+	ret, err := postJSON("api/task", map[string]any{"date": "21000101", "title": "Отчёт с тегом",
+		"tags": []string{" Работа" + suffix, work}}, http.MethodPost)
+	require.NoError(t, err)
+	require.NotNil(t, ret["id"], ret)
+	tagged := fmt.Sprint(ret["id"])
+	ret, err = postJSON("api/task", map[string]any{"date": "21000101", "title": "Отчёт",
+		"tags": []string{"два слова"}}, http.MethodPost)
+	assert.NoError(t, err)
+	assert.NotEmpty(t, ret["error"])
+
+	ret, err = postJSON("api/task?id="+tagged, nil, http.MethodGet)
+	assert.NoError(t, err)
+	assert.Equal(t, []any{work}, ret["tags"])
+	tag := findTag(t, work)
+	require.NotNil(t, tag)
+	assert.EqualValues(t, 1, tag["tasks"])
+	workID := fmt.Sprint(tag["id"])
+
+	ret, err = postJSON("api/tags", map[string]any{"name": home}, http.MethodPost)
+	require.NoError(t, err)
+	require.NotNil(t, ret["id"], ret)
+	homeID := fmt.Sprint(ret["id"])
+	ret, err = postJSON("api/tags", map[string]any{"name": home}, http.MethodPost)
+	assert.NoError(t, err)
+	assert.NotEmpty(t, ret["error"])
+
+	// Editing a task without tags keeps them; an empty list clears them.
+	ret, err = postJSON("api/task", map[string]any{"id": tagged, "date": "21000101", "title": "Отчёт с тегом"}, http.MethodPut)
+	assert.NoError(t, err)
+	assert.Empty(t, ret)
+	assert.Equal(t, []string{"Отчёт с тегом"}, taggedTitles(t, "tag="+url.QueryEscape(work)))
+	assert.Equal(t, []string{"Отчёт с тегом"}, taggedTitles(t, "search="+url.QueryEscape("tag:"+work)))
+	ret, err = postJSON("api/task", map[string]any{"id": tagged, "date": "21000101", "title": "Отчёт с тегом",
+		"tags": []string{home}}, http.MethodPut)
+	assert.NoError(t, err)
+	assert.Empty(t, ret)
+	assert.Empty(t, taggedTitles(t, "tag="+url.QueryEscape(work)))
+	ret, err = postJSON("api/tasks?tag="+url.QueryEscape(work)+"&search=Отчёт", nil, http.MethodGet)
+	assert.NoError(t, err)
+	assert.NotEmpty(t, ret["error"])
+
+	ret, err = postJSON("api/tags/merge?from="+homeID+"&into="+workID, nil, http.MethodPost)
+	assert.NoError(t, err)
+	assert.Empty(t, ret)
+	assert.Nil(t, findTag(t, home))
+	assert.Equal(t, []string{"Отчёт с тегом"}, taggedTitles(t, "tag="+url.QueryEscape(work)))
+
+	ret, err = postJSON("api/tags", map[string]any{"id": workID, "name": home}, http.MethodPut)
+	assert.NoError(t, err)
+	assert.Empty(t, ret)
+	assert.Equal(t, []string{"Отчёт с тегом"}, taggedTitles(t, "tag="+url.QueryEscape(home)))
+	ret, err = postJSON("api/tags", map[string]any{"id": "999999999", "name": work}, http.MethodPut)
+	assert.NoError(t, err)
+	assert.NotEmpty(t, ret["error"])
+
+	ret, err = postJSON("api/tags?id="+workID, nil, http.MethodDelete)
+	assert.NoError(t, err)
+	assert.Empty(t, ret)
+	ret, err = postJSON("api/task?id="+tagged, nil, http.MethodGet)
+	assert.NoError(t, err)
+	assert.Nil(t, ret["tags"])
+	ret, err = postJSON("api/tags?id="+workID, nil, http.MethodDelete)
+	assert.NoError(t, err)
+	assert.NotEmpty(t, ret["error"])
+
+	postJSON("api/task?id="+tagged, nil, http.MethodDelete)
+}
@@ -0,0 +1,52 @@
+package tests
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func listTasks(t *testing.T, query string) []map[string]string {
+	body, err := requestJSON("api/tasks?"+query, nil, http.MethodGet)
+	assert.NoError(t, err)
+
+	var m map[string][]map[string]string
+	err = json.Unmarshal(body, &m)
+	assert.NoError(t, err)
+	return m["tasks"]
+}
+
+func TestTaskList(t *testing.T) {
+	ids := []string{
+		addTask(t, task{date: "22000101", title: "Бассейн"}),
+		addTask(t, task{date: "22000102", title: "Английский", repeat: "d 1"}),
+		addTask(t, task{date: "22000103", title: "Врач"}),
+	}
+	defer func() {
+		for _, id := range ids {
+			_, err := postJSON("api/task?id="+id, nil, http.MethodDelete)
+			assert.NoError(t, err)
+		}
+	}()
+
+	period := "from=22000101&to=22000103"
+	assert.Equal(t, []string{"Бассейн", "Английский", "Врач"}, titles(listTasks(t, period)))
+	assert.Equal(t, []string{"Врач", "Английский", "Бассейн"}, titles(listTasks(t, period+"&order=desc")))
+	assert.Equal(t, []string{"Английский", "Бассейн", "Врач"}, titles(listTasks(t, period+"&sort=title")))
+	assert.Equal(t, []string{"Врач", "Английский", "Бассейн"}, titles(listTasks(t, period+"&sort=id&order=desc")))
+	assert.Equal(t, []string{"Английский"}, titles(listTasks(t, period+"&recurring=true")))
+	assert.Equal(t, []string{"Бассейн", "Врач"}, titles(listTasks(t, period+"&recurring=false")))
+	assert.Equal(t, []string{"Английский", "Врач"}, titles(listTasks(t, "from=22000102&to=22000131")))
+	assert.Equal(t, []string{"Бассейн", "Английский"}, titles(listTasks(t, period+"&limit=2")))
+	assert.Equal(t, []string{"Врач"}, titles(listTasks(t, period+"&limit=2&offset=2")))
+	assert.Len(t, listTasks(t, period+"&limit=100000"), 3)
+
+	for _, query := range []string{"from=2200", "to=22001301", "recurring=maybe", "sort=repeat",
+		"order=up", "limit=0", "limit=ten", "search=врач&sort=title"} {
+		ret, err := postJSON("api/tasks?"+query, nil, http.MethodGet)
+		assert.NoError(t, err)
+		assert.NotEmpty(t, ret["error"], "Ожидается ошибка для %q", query)
+	}
+}
@@ -0,0 +1,47 @@
+package tests
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTaskTime(t *testing.T) {
+	db := openDB(t)
+	defer db.Close()
+
+	today := time.Now().Format(`20060102`)
+	for _, v := range []string{"930", "2460", "09:30", "ab12"} {
+		m, err := postJSON("api/task", map[string]any{
+			"date":  today,
+			"time":  v,
+			"title": "Созвон",
+		}, http.MethodPost)
+		assert.NoError(t, err)
+		assert.NotEmpty(t, m["error"], "Ожидается ошибка для времени %q", v)
+	}
+
+	m, err := postJSON("api/task", map[string]any{
+		"date":  today,
+		"time":  "0930",
+		"title": "Созвон",
+	}, http.MethodPost)
+	assert.NoError(t, err)
+	assert.Empty(t, m["error"])
+	id := fmt.Sprint(m["id"])
+
+	var task Task
+	err = db.Get(&task, `SELECT * FROM scheduler WHERE id=?`, id)
+	assert.NoError(t, err)
+	assert.Equal(t, "0930", task.Time)
+
+	ret, err := postJSON("api/task?id="+id, nil, http.MethodGet)
+	assert.NoError(t, err)
+	assert.Equal(t, "0930", ret["time"])
+
+	_, err = postJSON("api/task?id="+id, nil, http.MethodDelete)
+	assert.NoError(t, err)
+}
@@ -0,0 +1,91 @@
+package tests
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func inTrash(t *testing.T, id string) map[string]any {
+	t.Helper()
+	ret, err := postJSON("api/trash", nil, http.MethodGet)
+	assert.NoError(t, err)
+	list, _ := ret["tasks"].([]any)
+	for _, item := range list {
+		if task := item.(map[string]any); task["id"] == id {
+			return task
+		}
+	}
+	return nil
+}
+
+func TestTrash(t *testing.T) {
+	db := openDB(t)
+	defer db.Close()
+
+	id := addTask(t, task{
+		date:  "21000101",
+		title: "Случайно удалённая задача",
+	})
+	ret, err := postJSON("api/task?id="+id, nil, http.MethodDelete)
+	assert.NoError(t, err)
+	assert.Empty(t, ret)
+	notFoundTask(t, id)
+
+	trashed := inTrash(t, id)
+	assert.NotNil(t, trashed)
+	if trashed != nil {
+		assert.Equal(t, "Случайно удалённая задача", trashed["title"])
+		assert.NotEmpty(t, trashed["deleted_at"])
+	}
+
+	ret, err = postJSON("api/task?id="+id, nil, http.MethodDelete)
+	assert.NoError(t, err)
+	assert.NotEmpty(t, ret["error"])
+
+	ret, err = postJSON("api/trash/restore?id="+id, nil, http.MethodPost)
+	assert.NoError(t, err)
+	assert.Empty(t, ret)
+	ret, err = postJSON("api/task?id="+id, nil, http.MethodGet)
+	assert.NoError(t, err)
+	assert.Equal(t, id, ret["id"])
+	assert.Empty(t, ret["deleted_at"])
+	assert.Nil(t, inTrash(t, id))
+
+	ret, err = postJSON("api/trash/restore?id="+id, nil, http.MethodPost)
+	assert.NoError(t, err)
+	assert.NotEmpty(t, ret["error"])
+
+	ret, err = postJSON("api/task/done?id="+id, nil, http.MethodPost)
+	assert.NoError(t, err)
+	assert.Empty(t, ret)
+	notFoundTask(t, id)
+	assert.NotNil(t, inTrash(t, id))
+
+	ret, err = postJSON("api/trash?id="+id, nil, http.MethodDelete)
+	assert.NoError(t, err)
+	assert.Empty(t, ret)
+	assert.Nil(t, inTrash(t, id))
+	var n int
+	err = db.Get(&n, `SELECT count(*) FROM scheduler WHERE id=?`, id)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, n)
+
+	ret, err = postJSON("api/trash?id="+id, nil, http.MethodDelete)
+	assert.NoError(t, err)
+	assert.NotEmpty(t, ret["error"])
+
+	id = addTask(t, task{
+		date:  "21000101",
+		title: "Ещё одна задача",
+	})
+	_, err = postJSON("api/task?id="+id, nil, http.MethodDelete)
+	assert.NoError(t, err)
+	ret, err = postJSON("api/trash", nil, http.MethodDelete)
+	assert.NoError(t, err)
+	assert.Empty(t, ret)
+	ret, err = postJSON("api/trash", nil, http.MethodGet)
+	assert.NoError(t, err)
+	assert.Empty(t, ret["tasks"])
+}
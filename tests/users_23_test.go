@@ -0,0 +1,143 @@
+package tests
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// requestAs sends a request with the given token, or with none if it is
+// empty, and returns the status and decoded body.
+func requestAs(t *testing.T, token string, apipath string, values map[string]any, method string) (int, map[string]any) {
+	t.Helper()
+	var data []byte
+	if len(values) > 0 {
+		var err error
+		data, err = json.Marshal(values)
+		require.NoError(t, err)
+	}
+	req, err := http.NewRequest(method, getURL(apipath), bytes.NewBuffer(data))
+	require.NoError(t, err)
+	req.Header.Set("Content-Type", "application/json")
+	if token != "" {
+		req.AddCookie(&http.Cookie{Name: "token", Value: token})
+	}
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	var m map[string]any
+	if len(body) > 0 {
+		json.Unmarshal(body, &m)
+	}
+	return resp.StatusCode, m
+}
+
+func signIn(t *testing.T, values map[string]any) string {
+	t.Helper()
+	status, ret := requestAs(t, "", "api/signin", values, http.MethodPost)
+	require.Equal(t, http.StatusOK, status, ret)
+	token, _ := ret["token"].(string)
+	require.NotEmpty(t, token)
+	return token
+}
+
+func TestUsers(t *testing.T) {
+	password := os.Getenv("TODO_PASSWORD")
+	if password == "" {
+		password = "1"
+	}
+	status, ret := requestAs(t, "", "api/signin", map[string]any{"password": password}, http.MethodPost)
+	if status != http.StatusOK {
+		t.Skip("cannot sign in as admin: the server has no password or TODO_PASSWORD differs")
+	}
+	admin, _ := ret["token"].(string)
+	require.NotEmpty(t, admin)
+
+	status, _ = requestAs(t, "", "api/users", map[string]any{"login": "anna", "password": "secret"}, http.MethodPost)
+	assert.Equal(t, http.StatusUnauthorized, status)
+
+	status, ret = requestAs(t, admin, "api/users", map[string]any{"login": "anna", "password": "secret"}, http.MethodPost)
+	require.Equal(t, http.StatusOK, status, ret)
+	userID := jsonNumber(ret["id"])
+	require.NotEmpty(t, userID)
+	defer requestAs(t, admin, "api/users?id="+userID, nil, http.MethodDelete)
+
+	status, ret = requestAs(t, admin, "api/users", map[string]any{"login": "anna", "password": "other"}, http.MethodPost)
+	assert.Equal(t, http.StatusBadRequest, status)
+	assert.NotEmpty(t, ret["error"])
+	status, ret = requestAs(t, admin, "api/users", map[string]any{"login": "", "password": "secret"}, http.MethodPost)
+	assert.Equal(t, http.StatusBadRequest, status)
+	assert.NotEmpty(t, ret["error"])
+
+	status, ret = requestAs(t, admin, "api/users", nil, http.MethodGet)
+	assert.Equal(t, http.StatusOK, status)
+	list, _ := ret["users"].([]any)
+	require.Len(t, list, 1)
+	assert.Equal(t, "anna", list[0].(map[string]any)["login"])
+	assert.NotContains(t, list[0], "password_hash")
+
+	status, _ = requestAs(t, "", "api/tasks", nil, http.MethodGet)
+	assert.Equal(t, http.StatusUnauthorized, status)
+	status, ret = requestAs(t, "", "api/signin", map[string]any{"login": "anna", "password": "wrong"}, http.MethodPost)
+	assert.Equal(t, http.StatusBadRequest, status)
+	assert.NotEmpty(t, ret["error"])
+	anna := signIn(t, map[string]any{"login": "anna", "password": "secret"})
+
+	status, _ = requestAs(t, anna, "api/users", nil, http.MethodGet)
+	assert.Equal(t, http.StatusForbidden, status)
+	// Holidays are shared, so only the admin may change them.
+	status, _ = requestAs(t, anna, "api/holidays", map[string]any{"date": "21000301", "name": "Праздник Анны"}, http.MethodPost)
+	assert.Equal(t, http.StatusForbidden, status)
+	status, _ = requestAs(t, anna, "api/holidays?date=21000301", nil, http.MethodDelete)
+	assert.Equal(t, http.StatusForbidden, status)
+	status, _ = requestAs(t, anna, "api/holidays", nil, http.MethodGet)
+	assert.Equal(t, http.StatusOK, status)
+
+	status, ret = requestAs(t, anna, "api/task", map[string]any{"date": "21000101", "title": "Задача Анны"}, http.MethodPost)
+	require.Equal(t, http.StatusOK, status, ret)
+	taskID := jsonNumber(ret["id"])
+
+	status, ret = requestAs(t, anna, "api/task?id="+taskID, nil, http.MethodGet)
+	assert.Equal(t, http.StatusOK, status)
+	assert.Equal(t, "Задача Анны", ret["title"])
+	status, ret = requestAs(t, admin, "api/task?id="+taskID, nil, http.MethodGet)
+	assert.NotEqual(t, http.StatusOK, status)
+	assert.NotEmpty(t, ret["error"])
+	status, _ = requestAs(t, admin, "api/task?id="+taskID, nil, http.MethodDelete)
+	assert.NotEqual(t, http.StatusOK, status)
+
+	status, ret = requestAs(t, anna, "api/tasks", nil, http.MethodGet)
+	assert.Equal(t, http.StatusOK, status)
+	tasks, _ := ret["tasks"].([]any)
+	require.Len(t, tasks, 1)
+	assert.Equal(t, taskID, tasks[0].(map[string]any)["id"])
+
+	status, _ = requestAs(t, admin, "api/users?id="+userID, nil, http.MethodDelete)
+	assert.Equal(t, http.StatusOK, status)
+	status, _ = requestAs(t, anna, "api/tasks", nil, http.MethodGet)
+	assert.Equal(t, http.StatusUnauthorized, status)
+	status, _ = requestAs(t, admin, "api/tasks", nil, http.MethodGet)
+	assert.Equal(t, http.StatusOK, status)
+}
+
+// jsonNumber formats an id decoded from JSON, which may be a number or a
+// string.
+func jsonNumber(v any) string {
+	switch v := v.(type) {
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case string:
+		return v
+	}
+	return ""
+}